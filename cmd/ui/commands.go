@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/config"
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/service/integration"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui"
+	"github.com/francescarpi/mytime/internal/util"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"export":    {"export [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-tag TAG]", exportCommand},
+	"history":   {"history <task id>", historyCommand},
+	"profiles":  {"profiles", profilesCommand},
+	"reconcile": {"reconcile [-from YYYY-MM-DD] [-to YYYY-MM-DD]", reconcileCommand},
+	"search":    {"search [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-limit N] <query> | -reindex", searchCommand},
+	"sync":      {"sync -retry", syncCommand},
+	"tags":      {"tags [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-tag TAG]", tagsCommand},
+}
+
+func runCommand(args []string) int {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\nCommands:\n", args[0])
+		for _, name := range slices.Sorted(maps.Keys(commands)) {
+			fmt.Fprintf(os.Stderr, "  mytime %s\n", commands[name].usage)
+		}
+		return 2
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func reconcileCommand(args []string) error {
+	monday, sunday := util.WeekBounds(time.Now())
+
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	from := flags.String("from", monday.Format(time.DateOnly), "First day to compare")
+	to := flags.String("to", sunday.Format(time.DateOnly), "Last day to compare")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fromDate, toDate, err := parseDates(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	rows, err := deps.Integration.Reconcile(context.Background(), deps.Service, fromDate, toDate)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tINTEGRATION\tEXT.ID\tLOCAL\tREPORTED\tREMOTE\tSTATUS")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Date,
+			row.Integration,
+			row.ExternalId,
+			util.HumanizeDuration(row.Local),
+			util.HumanizeDuration(row.Reported),
+			util.HumanizeDuration(row.Remote),
+			row.Status,
+		)
+	}
+	return w.Flush()
+}
+
+func syncCommand(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	retry := flags.Bool("retry", false, "Send again the entries in the outbox")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !*retry {
+		return errors.New("only -retry is supported, use the sync view to send new tasks")
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	entries, err := deps.Service.GetOutbox()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("The outbox is empty")
+		return nil
+	}
+
+	results := deps.Integration.SendOutbox(context.Background(), deps.Service, entries)
+	for _, result := range results {
+		if result.Discarded {
+			fmt.Printf("Outbox entry %d (tasks %s): discarded, %s\n", result.Entry.ID, result.Entry.TaskIds, result.Err)
+			continue
+		}
+
+		status := "sent"
+		if result.Err != nil {
+			status = "failed: " + result.Err.Error()
+		}
+		fmt.Printf("%s %s %s (%s): %s\n",
+			result.Task.Date,
+			result.Task.ExternalId,
+			result.Task.Desc,
+			util.HumanizeDuration(result.Task.Duration),
+			status,
+		)
+	}
+	_, failed, _ := integration.CountOutboxResults(results)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries could not be sent", failed, len(entries))
+	}
+	return nil
+}
+
+func profilesCommand(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: mytime profiles")
+	}
+
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	current, err := config.Load(profile)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tDATABASE")
+	for _, name := range profiles.Names() {
+		cfg, err := config.Load(name)
+		if err != nil {
+			return err
+		}
+
+		// hide the password of the DSN
+		dsn := cfg.DBUrl
+		if u, err := url.Parse(dsn); err == nil {
+			dsn = u.Redacted()
+		}
+
+		active := ""
+		if name == current.Profile {
+			active = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", active, name, dsn)
+	}
+	return w.Flush()
+}
+
+func historyCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: mytime history <task id>")
+	}
+
+	id, err := strconv.ParseUint(args[0], 10, 0)
+	if err != nil {
+		return fmt.Errorf("invalid task id: %s", args[0])
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	entries, err := deps.Service.GetTaskHistory(uint(id))
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No history for task %d\n", id)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tACTION\tCHANGES")
+	for _, entry := range entries {
+		changes, err := service.HistoryChanges(entry)
+		if err != nil {
+			return err
+		}
+
+		var described []string
+		for _, change := range changes {
+			described = append(described, fmt.Sprintf("%s: %q -> %q", change.Field, change.Before, change.After))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			entry.CreatedAt.Format("2006-01-02 15:04:05"),
+			entry.Action,
+			strings.Join(described, ", "),
+		)
+	}
+	return w.Flush()
+}
+
+// exportCommand writes the tasks between both dates as CSV, with their tags.
+func exportCommand(args []string) error {
+	monday, sunday := util.WeekBounds(time.Now())
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := flags.String("from", monday.Format(time.DateOnly), "First day to export")
+	to := flags.String("to", sunday.Format(time.DateOnly), "Last day to export")
+	tag := flags.String("tag", "", "Export only the tasks with this tag")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fromDate, toDate, err := parseDates(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	tasks, err := deps.Service.Repo.GetTasksBetween(fromDate, toDate)
+	if err != nil {
+		return err
+	}
+	tags, err := deps.Service.GetTaskTags(tasks)
+	if err != nil {
+		return err
+	}
+	tasks = service.TasksWithTag(tasks, tags, *tag)
+	slices.SortFunc(tasks, func(a, b model.Task) int { return a.Start.Compare(b.Start.Time) })
+
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"id", "date", "start", "end", "duration", "project", "description", "external_id", "reported", "tags"})
+	for _, task := range tasks {
+		end := ""
+		if task.End != nil {
+			end = task.End.Format(time.TimeOnly)
+		}
+
+		externalId := ""
+		if task.ExternalId != nil {
+			externalId = *task.ExternalId
+		}
+
+		w.Write([]string{
+			strconv.FormatUint(uint64(task.ID), 10),
+			task.Start.Format(time.DateOnly),
+			task.Start.Format(time.TimeOnly),
+			end,
+			strconv.Itoa(task.Duration),
+			task.ProjectName(),
+			task.Desc,
+			externalId,
+			strconv.FormatBool(task.Reported),
+			strings.Join(tags[task.ID], ","),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// tagsCommand prints the time tracked by day and tag between both dates, and
+// the total of each tag.
+func tagsCommand(args []string) error {
+	monday, sunday := util.WeekBounds(time.Now())
+
+	flags := flag.NewFlagSet("tags", flag.ContinueOnError)
+	from := flags.String("from", monday.Format(time.DateOnly), "First day to report")
+	to := flags.String("to", sunday.Format(time.DateOnly), "Last day to report")
+	tag := flags.String("tag", "", "Report only this tag")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fromDate, toDate, err := parseDates(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	durations, err := deps.Service.GetTagDurations(fromDate, toDate)
+	if err != nil {
+		return err
+	}
+
+	only := strings.ToLower(strings.TrimLeft(*tag, "#"))
+	totals := map[string]int{}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tTAG\tDURATION")
+	for _, duration := range durations {
+		if only != "" && duration.Tag != only {
+			continue
+		}
+		totals[duration.Tag] += duration.Duration
+		fmt.Fprintf(w, "%s\t#%s\t%s\n", duration.Date, duration.Tag, util.HumanizeDuration(duration.Duration))
+	}
+	for _, name := range slices.Sorted(maps.Keys(totals)) {
+		fmt.Fprintf(w, "TOTAL\t#%s\t%s\n", name, util.HumanizeDuration(totals[name]))
+	}
+	return w.Flush()
+}
+
+// searchCommand prints the tasks matching the query, grouped by day, the most
+// recent first.
+func searchCommand(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	from := flags.String("from", "", "First day to search")
+	to := flags.String("to", "", "Last day to search")
+	limit := flags.Int("limit", service.SEARCH_LIMIT, "Maximum number of tasks")
+	reindex := flags.Bool("reindex", false, "Create the search index again")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *reindex {
+		deps, err := ui.NewDependencies(profile)
+		if err != nil {
+			return err
+		}
+		if err := deps.Service.RebuildSearchIndex(); err != nil {
+			return err
+		}
+		fmt.Println("Search index rebuilt")
+		return nil
+	}
+
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return errors.New("usage: mytime search [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-limit N] <query> | -reindex")
+	}
+
+	var dateRange types.DateRange
+	var err error
+	if *from != "" {
+		if dateRange.From, err = time.ParseInLocation(time.DateOnly, *from, time.Local); err != nil {
+			return err
+		}
+	}
+	if *to != "" {
+		if dateRange.To, err = time.ParseInLocation(time.DateOnly, *to, time.Local); err != nil {
+			return err
+		}
+	}
+
+	deps, err := ui.NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+	days, err := deps.Service.SearchTasks(query, dateRange, *limit)
+	if err != nil {
+		return err
+	}
+
+	if len(days) == 0 {
+		fmt.Printf("No tasks found for %q\n", query)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, day := range days {
+		fmt.Fprintf(w, "%s\t\t\t\t%s\n", day.Date, util.HumanizeDuration(day.Duration))
+		for _, task := range day.Tasks {
+			externalId := ""
+			if task.ExternalId != nil {
+				externalId = *task.ExternalId
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+				task.Start.Format("15:04"),
+				task.ProjectName(),
+				task.Desc,
+				externalId,
+				util.HumanizeDuration(task.Duration),
+			)
+		}
+	}
+	return w.Flush()
+}
+
+func parseDates(from, to string) (time.Time, time.Time, error) {
+	fromDate, err := time.ParseInLocation(time.DateOnly, from, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	toDate, err := time.ParseInLocation(time.DateOnly, to, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return fromDate, toDate, nil
+}
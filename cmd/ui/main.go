@@ -10,8 +10,13 @@ import (
 	"github.com/francescarpi/mytime/internal/ui"
 )
 
+// profile is the one selected with --profile, or empty for MYTIME_PROFILE or
+// the default one.
+var profile string
+
 func main() {
 	logsOn := flag.Bool("logs", false, "Enable logs to file")
+	flag.StringVar(&profile, "profile", "", "Profile to use, instead of MYTIME_PROFILE or the default one")
 	flag.Parse()
 
 	logFile := setupLogging(*logsOn)
@@ -19,7 +24,21 @@ func main() {
 		defer logFile.Close()
 	}
 
-	ui.StartApp()
+	if flag.NArg() > 0 {
+		code := runCommand(flag.Args())
+		if logFile != nil {
+			logFile.Close()
+		}
+		os.Exit(code)
+	}
+
+	if err := ui.StartApp(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if logFile != nil {
+			logFile.Close()
+		}
+		os.Exit(1)
+	}
 }
 
 func setupLogging(enabled bool) *os.File {
@@ -1,17 +1,128 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+)
+
+const (
+	DEFAULT_TRASH_DAYS = 30
+	DEFAULT_PROFILE    = "default"
 )
 
 type Config struct {
-	DBUrl string
+	Profile   string
+	DBUrl     string
+	TrashDays int
+}
+
+// Profile is an entry of the profiles file. The fields left out take the
+// defaults of the profile.
+type Profile struct {
+	DBUrl     string `json:"db_url"`
+	TrashDays *int   `json:"trash_days"`
+}
+
+// Profiles is the content of the profiles file: the profile used when none is
+// selected, and the profiles by name.
+type Profiles struct {
+	Default  string             `json:"default"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Names returns the default profile followed by the others, sorted.
+func (p Profiles) Names() []string {
+	names := []string{DEFAULT_PROFILE}
+	for _, name := range slices.Sorted(maps.Keys(p.Profiles)) {
+		if name != DEFAULT_PROFILE {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// LoadProfiles reads the profiles file, MYTIME_PROFILES or
+// $HOME/.config/mytime/profiles.json. A missing file has no profiles.
+func LoadProfiles() (Profiles, error) {
+	path := os.Getenv("MYTIME_PROFILES")
+	if path == "" {
+		path = fmt.Sprintf("%s/.config/mytime/profiles.json", os.Getenv("HOME"))
+	}
+
+	var profiles Profiles
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return profiles, err
+	}
+	if err := json.Unmarshal(content, &profiles); err != nil {
+		return profiles, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	return profiles, nil
 }
 
-func Load() Config {
+// Load returns the configuration of the profile, or else of MYTIME_PROFILE,
+// or else of the default one of the profiles file.
+//
+// A profile uses the db_url of the profiles file, or else
+// $HOME/.local/share/mytime/<profile>.sqlite. MYTIME_DB_URL overrides the
+// database of the default profile, e.g. with a postgres:// DSN, and
+// MYTIME_TRASH_DAYS the days the deleted tasks are kept, 0 to keep them
+// forever, unless the profile sets trash_days.
+func Load(profile string) (Config, error) {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return Config{}, err
+	}
+	return load(profiles, profile)
+}
+
+func load(profiles Profiles, profile string) (Config, error) {
+	if profile == "" {
+		profile = os.Getenv("MYTIME_PROFILE")
+	}
+	if profile == "" {
+		profile = profiles.Default
+	}
+	if profile == "" {
+		profile = DEFAULT_PROFILE
+	}
+
+	entry, ok := profiles.Profiles[profile]
+	if !ok && profile != DEFAULT_PROFILE {
+		return Config{}, fmt.Errorf("unknown profile %s", profile)
+	}
+
+	cfg := Config{
+		Profile:   profile,
+		DBUrl:     entry.DBUrl,
+		TrashDays: DEFAULT_TRASH_DAYS,
+	}
+
 	home := os.Getenv("HOME")
-	return Config{
-		DBUrl: fmt.Sprintf("file://%s/.local/share/mytime/mytime.sqlite", home),
+	if cfg.DBUrl == "" && profile == DEFAULT_PROFILE {
+		cfg.DBUrl = os.Getenv("MYTIME_DB_URL")
+		if cfg.DBUrl == "" {
+			cfg.DBUrl = fmt.Sprintf("file://%s/.local/share/mytime/mytime.sqlite", home)
+		}
+	}
+	if cfg.DBUrl == "" {
+		cfg.DBUrl = fmt.Sprintf("file://%s/.local/share/mytime/%s.sqlite", home, profile)
 	}
+
+	if days, err := strconv.Atoi(os.Getenv("MYTIME_TRASH_DAYS")); err == nil {
+		cfg.TrashDays = days
+	}
+	if entry.TrashDays != nil {
+		cfg.TrashDays = *entry.TrashDays
+	}
+
+	return cfg, nil
 }
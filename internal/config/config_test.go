@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	t.Setenv("MYTIME_PROFILE", "")
+	t.Setenv("MYTIME_DB_URL", "")
+	t.Setenv("MYTIME_TRASH_DAYS", "")
+
+	days := 90
+	profiles := Profiles{
+		Profiles: map[string]Profile{
+			"work":     {DBUrl: "postgres://work", TrashDays: &days},
+			"personal": {},
+		},
+	}
+
+	if names := profiles.Names(); !slices.Equal(names, []string{"default", "personal", "work"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	cfg, err := load(profiles, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != DEFAULT_PROFILE || cfg.DBUrl != "file:///home/me/.local/share/mytime/mytime.sqlite" || cfg.TrashDays != DEFAULT_TRASH_DAYS {
+		t.Errorf("unexpected default config %+v", cfg)
+	}
+
+	cfg, err = load(profiles, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBUrl != "postgres://work" || cfg.TrashDays != 90 {
+		t.Errorf("unexpected work config %+v", cfg)
+	}
+
+	// the profile of the environment, with the database named after it
+	t.Setenv("MYTIME_PROFILE", "personal")
+	t.Setenv("MYTIME_DB_URL", "postgres://other")
+	cfg, err = load(profiles, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != "personal" || cfg.DBUrl != "file:///home/me/.local/share/mytime/personal.sqlite" {
+		t.Errorf("unexpected personal config %+v", cfg)
+	}
+
+	// MYTIME_DB_URL only applies to the default profile
+	cfg, err = load(profiles, DEFAULT_PROFILE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBUrl != "postgres://other" {
+		t.Errorf("unexpected default database %s", cfg.DBUrl)
+	}
+
+	if _, err := load(profiles, "client-x"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+
+	t.Setenv("MYTIME_PROFILE", "")
+	profiles.Default = "work"
+	if cfg, _ := load(profiles, ""); cfg.Profile != "work" {
+		t.Errorf("expected the default profile of the file, got %s", cfg.Profile)
+	}
+}
+
+func TestLoadProfilesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	t.Setenv("MYTIME_PROFILES", path)
+
+	profiles, err := LoadProfiles()
+	if err != nil || len(profiles.Profiles) != 0 {
+		t.Fatalf("expected no profiles without file, got %+v %v", profiles, err)
+	}
+
+	content := `{"default": "work", "profiles": {"work": {"db_url": "postgres://work"}, "client-x": {}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err = LoadProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles.Default != "work" || profiles.Profiles["work"].DBUrl != "postgres://work" {
+		t.Errorf("unexpected profiles %+v", profiles)
+	}
+	if names := profiles.Names(); !slices.Equal(names, []string{"default", "client-x", "work"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+type CacheEntry struct {
+	Key       string         `gorm:"primarykey;type:varchar"`
+	Value     string         `gorm:"not null;type:text"`
+	UpdatedAt LocalTimestamp `gorm:"not null;type:timestamp"`
+}
+
+func (CacheEntry) TableName() string {
+	return "integration_cache"
+}
@@ -0,0 +1,18 @@
+package model
+
+// OutboxEntry is a sync group that failed to be sent, or that was deferred,
+// waiting to be sent again. TaskIds holds the comma separated ids of its tasks.
+type OutboxEntry struct {
+	ID          uint           `gorm:"primarykey"`
+	Integration string         `gorm:"not null;type:varchar"`
+	TaskIds     string         `gorm:"not null;type:varchar"`
+	Payload     string         `gorm:"not null;type:text"`
+	Attempts    int            `gorm:"not null;default:0"`
+	LastError   string         `gorm:"not null;default:'';type:text"`
+	CreatedAt   LocalTimestamp `gorm:"not null;type:timestamp"`
+	UpdatedAt   LocalTimestamp `gorm:"not null;type:timestamp"`
+}
+
+func (OutboxEntry) TableName() string {
+	return "outbox"
+}
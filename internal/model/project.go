@@ -0,0 +1,24 @@
+package model
+
+// Project holds the metadata of the projects the tasks are tracked for. The
+// tasks reference it by name, in their project column, as the tasks table is
+// shared with the Rust app.
+type Project struct {
+	ID                 uint    `gorm:"primarykey"`
+	Name               string  `gorm:"not null;uniqueIndex;type:varchar"`
+	Color              string  `gorm:"not null;default:'';type:varchar"`
+	Client             string  `gorm:"not null;default:'';type:varchar"`
+	HourlyRate         float64 `gorm:"not null;default:0"`
+	DefaultExternalId  string  `gorm:"not null;default:'';type:varchar"`
+	DefaultIntegration string  `gorm:"not null;default:'';type:varchar"`
+	DefaultActivityId  int     `gorm:"not null;default:0"`
+	Archived           bool    `gorm:"not null;default:false"`
+}
+
+// Label returns the name of the project in its color, for the views.
+func (p *Project) Label() string {
+	if p.Color == "" {
+		return p.Name
+	}
+	return "[" + p.Color + "]" + p.Name + "[-]"
+}
@@ -0,0 +1,15 @@
+package model
+
+// RemoteEntry links a local task with the time entry it was synced to. All the
+// tasks of a sync group share the same remote entry. Instance and IssueId are
+// where the entry was recorded, as the external id of the tasks may be edited
+// afterwards.
+type RemoteEntry struct {
+	ID          uint           `gorm:"primarykey"`
+	TaskId      uint           `gorm:"not null;index"`
+	Integration string         `gorm:"not null;type:varchar"`
+	Instance    string         `gorm:"not null;default:'';type:varchar"`
+	IssueId     string         `gorm:"not null;default:'';type:varchar"`
+	RemoteId    string         `gorm:"not null;type:varchar"`
+	CreatedAt   LocalTimestamp `gorm:"not null;type:timestamp"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// SchemaVersion records a migration applied to the database by this app. The
+// tables of the Rust app are tracked by its own migrations.
+type SchemaVersion struct {
+	Version   int            `gorm:"primarykey;autoIncrement:false"`
+	Name      string         `gorm:"not null;type:varchar"`
+	AppliedAt LocalTimestamp `gorm:"not null;type:timestamp"`
+}
+
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
@@ -23,6 +23,18 @@ type Settings struct {
 	cachedHoursValid bool
 }
 
+// DefaultSettings are the settings of a new database.
+func DefaultSettings() Settings {
+	return Settings{
+		ID:                1,
+		WorkHours:         "8,8,8,8,8,0,0",
+		Theme:             "default",
+		ViewType:          "chronological",
+		ThemeSecondary:    "#ce93d8",
+		IntegrationConfig: "{}",
+	}
+}
+
 func (s *Settings) GoalDayInSeconds(date time.Time) int {
 	hours, err := s.parsedWorkHours()
 	if err != nil {
@@ -0,0 +1,14 @@
+package model
+
+// Tag classifies tasks across projects, like meeting, review or support.
+type Tag struct {
+	ID   uint   `gorm:"primarykey"`
+	Name string `gorm:"not null;uniqueIndex;type:varchar"`
+}
+
+// TaskTag links a task to one of its tags. It is kept apart from the tasks
+// table, which is shared with the Rust app.
+type TaskTag struct {
+	TaskId uint `gorm:"primaryKey;autoIncrement:false"`
+	TagId  uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
@@ -22,3 +22,10 @@ func (t *Task) ReportedIcon() string {
 func (t *Task) IsOpen() bool {
 	return t.End == nil
 }
+
+func (t *Task) ProjectName() string {
+	if t.Project == nil {
+		return ""
+	}
+	return *t.Project
+}
@@ -0,0 +1,27 @@
+package model
+
+const (
+	HISTORY_CREATE  = "create"
+	HISTORY_UPDATE  = "update"
+	HISTORY_DELETE  = "delete"
+	HISTORY_REPORT  = "report"
+	HISTORY_RESTORE = "restore"
+	HISTORY_UNDO    = "undo"
+	HISTORY_REDO    = "redo"
+)
+
+// TaskHistory records a change of a task, with the JSON snapshots of the task
+// before and after it. Before is empty when the task was created, and After
+// when it was deleted.
+type TaskHistory struct {
+	ID        uint           `gorm:"primarykey"`
+	TaskId    uint           `gorm:"not null;index"`
+	Action    string         `gorm:"not null;type:varchar"`
+	Before    string         `gorm:"not null;default:'';type:text"`
+	After     string         `gorm:"not null;default:'';type:text"`
+	CreatedAt LocalTimestamp `gorm:"not null;type:timestamp"`
+}
+
+func (TaskHistory) TableName() string {
+	return "task_history"
+}
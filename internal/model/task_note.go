@@ -0,0 +1,10 @@
+package model
+
+// TaskNote holds the markdown notes of a task. They are kept apart from the
+// tasks table, which is shared with the Rust app, and from the description,
+// which is the comment and the grouping key of the synced entries.
+type TaskNote struct {
+	TaskId    uint           `gorm:"primaryKey;autoIncrement:false"`
+	Notes     string         `gorm:"not null;type:text"`
+	UpdatedAt LocalTimestamp `gorm:"not null;type:timestamp"`
+}
@@ -0,0 +1,15 @@
+package model
+
+// TrashEntry is a deleted task, kept as a JSON snapshot to be restored until
+// it is purged. The tasks table is shared with the Rust app, so the deleted
+// tasks are moved here instead of being flagged there.
+type TrashEntry struct {
+	ID        uint           `gorm:"primarykey"`
+	TaskId    uint           `gorm:"not null;index"`
+	Task      string         `gorm:"not null;type:text"`
+	DeletedAt LocalTimestamp `gorm:"not null;type:timestamp"`
+}
+
+func (TrashEntry) TableName() string {
+	return "trash"
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "time"
+
+// Clock returns the current time. The repositories use it to timestamp the
+// changes and to compute the duration of the open tasks.
+type Clock func() time.Time
+
+// FakeClock is a Clock that only moves when told to.
+type FakeClock struct {
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *FakeClock) Set(now time.Time) {
+	c.now = now
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
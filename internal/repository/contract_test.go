@@ -0,0 +1,531 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// A Wednesday of a week with a single digit number.
+var contractStart = time.Date(2025, 2, 26, 9, 0, 0, 0, time.Local)
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T, clock Clock) Repository {
+		return NewMemoryRepository(clock)
+	})
+}
+
+func TestSqliteRepositoryContract(t *testing.T) {
+	testRepositoryContract(t, func(t *testing.T, clock Clock) Repository {
+		repo := mustOpen(t)(NewSqliteRepository(filepath.Join(t.TempDir(), "mytime.sqlite")))
+		repo.clock = clock
+		return repo
+	})
+}
+
+// TestPostgresRepositoryContract runs against the empty database of
+// MYTIME_TEST_POSTGRES_URL, whose tables are truncated by each test.
+func TestPostgresRepositoryContract(t *testing.T) {
+	dsn := os.Getenv("MYTIME_TEST_POSTGRES_URL")
+	if dsn == "" {
+		t.Skip("MYTIME_TEST_POSTGRES_URL not set")
+	}
+
+	testRepositoryContract(t, func(t *testing.T, clock Clock) Repository {
+		repo := mustOpen(t)(NewPostgresRepository(dsn))
+		if err := repo.db.Exec("TRUNCATE tasks, integration_cache, remote_entries, outbox, task_history, trash, projects, tags, task_tags, task_notes RESTART IDENTITY").Error; err != nil {
+			t.Fatal(err)
+		}
+		repo.clock = clock
+		return repo
+	})
+}
+
+// testRepositoryContract checks the behaviour every Repository must share.
+func testRepositoryContract(t *testing.T, newRepository func(t *testing.T, clock Clock) Repository) {
+	t.Run("DefaultSettings", func(t *testing.T) {
+		repo := newRepository(t, NewFakeClock(contractStart).Now)
+
+		settings, err := repo.GetSettings()
+		must(t, err)
+		if settings.WorkHours != "8,8,8,8,8,0,0" || settings.ViewType != "chronological" || settings.IntegrationConfig != "{}" {
+			t.Errorf("expected the default settings, got %+v", settings)
+		}
+	})
+
+	t.Run("OpenTaskDuration", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		must(t, repo.CreateTask("open", nil, nil, nil))
+		clock.Advance(30 * time.Minute)
+
+		tasks, err := repo.GetTasksByDate(clock.Now())
+		must(t, err)
+		if len(tasks) != 1 || !tasks[0].IsOpen() || tasks[0].Duration != 1800 {
+			t.Fatalf("expected an open task of 1800s, got %+v", tasks)
+		}
+
+		clock.Advance(15 * time.Minute)
+		expectDuration(t, "daily", 2700)(repo.GetWorkedDurationForDate(clock.Now(), types.All))
+
+		must(t, repo.CloseOpenedTasks())
+		clock.Advance(time.Hour)
+		expectDuration(t, "closed", 2700)(repo.GetWorkedDurationForDate(clock.Now(), types.All))
+	})
+
+	t.Run("WeeklyDuration", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		// Sunday before, Monday, Wednesday, Sunday and the Monday after
+		for i, days := range []int{-3, -2, 0, 4, 5} {
+			clock.Set(contractStart.AddDate(0, 0, days))
+			must(t, repo.CreateTask("task", nil, nil, nil))
+			clock.Advance(time.Duration(i+1) * time.Hour)
+			must(t, repo.CloseOpenedTasks())
+		}
+		must(t, repo.SetTaskAsReported(3))
+
+		expectDuration(t, "weekly", (2+3+4)*3600)(repo.GetWeeklyWorkedDurationForDate(contractStart))
+		expectDuration(t, "reported", 3*3600)(repo.GetWorkedDurationForDate(contractStart, types.Reported))
+		expectDuration(t, "not reported", 0)(repo.GetWorkedDurationForDate(contractStart, types.NotReported))
+
+		tasks, err := repo.GetTasksBetween(contractStart.AddDate(0, 0, -2), contractStart)
+		must(t, err)
+		if ids := taskIds(tasks); !slices.Equal(ids, []uint{3, 2}) {
+			t.Fatalf("expected tasks [3 2] between Monday and Wednesday, got %v", ids)
+		}
+	})
+
+	t.Run("SyncGrouping", func(t *testing.T) {
+		clock := NewFakeClock(contractStart.AddDate(0, 0, -1))
+		repo := newRepository(t, clock.Now)
+
+		issue, other, project := "ISSUE-1", "", "mytime"
+		track := func(desc string, project, externalId *string, minutes int) {
+			must(t, repo.CreateTask(desc, project, externalId, nil))
+			clock.Advance(time.Duration(minutes) * time.Minute)
+			must(t, repo.CloseOpenedTasks())
+		}
+
+		track("review", nil, &issue, 10) // 1, the day before
+		clock.Set(contractStart)
+		track("review", nil, &issue, 20)     // 2
+		track("coding", nil, &issue, 30)     // 3
+		track("review", nil, &issue, 40)     // 4, grouped with 2
+		track("review", &project, &issue, 5) // 5, another project
+		track("meeting", nil, nil, 60)       // 6, no external id
+		track("meeting", nil, &other, 60)    // 7, empty external id
+		track("review", nil, &issue, 15)     // 8, reported
+		must(t, repo.SetTaskAsReported(8))
+		must(t, repo.CreateTask("review", nil, &issue, nil)) // 9, open
+
+		groups, err := repo.GetTasksToSync()
+		must(t, err)
+
+		expected := []types.TasksToSync{
+			{Id: "2-4", ExternalId: issue, Duration: 3600, Desc: "review", Date: "2025-02-26", Ids: types.ListOfIds{IDs: []string{"2", "4"}}},
+			{Id: "3", ExternalId: issue, Duration: 1800, Desc: "coding", Date: "2025-02-26", Ids: types.ListOfIds{IDs: []string{"3"}}},
+			{Id: "5", ExternalId: issue, Duration: 300, Desc: "review", Date: "2025-02-26", Project: project, Ids: types.ListOfIds{IDs: []string{"5"}}},
+			{Id: "1", ExternalId: issue, Duration: 600, Desc: "review", Date: "2025-02-25", Ids: types.ListOfIds{IDs: []string{"1"}}},
+		}
+		if len(groups) != len(expected) {
+			t.Fatalf("expected %d groups, got %+v", len(expected), groups)
+		}
+		for i, group := range groups {
+			if group.Id != expected[i].Id || group.ExternalId != expected[i].ExternalId ||
+				group.Duration != expected[i].Duration || group.Desc != expected[i].Desc ||
+				group.Date != expected[i].Date || group.Project != expected[i].Project ||
+				!slices.Equal(group.Ids.IDs, expected[i].Ids.IDs) {
+				t.Errorf("group %d: expected %+v, got %+v", i, expected[i], group)
+			}
+		}
+	})
+
+	t.Run("CloseAndReopen", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		must(t, repo.CreateTask("first", nil, nil, nil))
+		clock.Advance(time.Hour)
+		must(t, repo.CloseTask(1))
+		if err := repo.CloseTask(1); err == nil {
+			t.Error("expected an error closing a closed task")
+		}
+		if err := repo.CloseTask(99); err == nil {
+			t.Error("expected an error closing a missing task")
+		}
+
+		// Reopening a task starts a new one, leaving the closed one untouched
+		clock.Advance(time.Hour)
+		must(t, repo.CreateTask("first", nil, nil, nil))
+		clock.Advance(30 * time.Minute)
+		must(t, repo.CloseOpenedTasks())
+
+		first, err := repo.GetTask(1)
+		must(t, err)
+		second, err := repo.GetTask(2)
+		must(t, err)
+
+		expectTime(t, "first end", first.End, "10:00:00")
+		expectTime(t, "second start", &second.Start, "11:00:00")
+		expectTime(t, "second end", second.End, "11:30:00")
+		expectDuration(t, "daily", 5400)(repo.GetWorkedDurationForDate(contractStart, types.All))
+
+		if _, err := repo.GetTask(99); err == nil {
+			t.Error("expected an error getting a missing task")
+		}
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		issue := "ISSUE-1"
+		for _, desc := range []string{"reported", "kept"} {
+			must(t, repo.CreateTask(desc, nil, &issue, nil))
+			clock.Advance(time.Hour)
+			must(t, repo.CloseOpenedTasks())
+		}
+		must(t, repo.SetTaskAsReported(1))
+		must(t, repo.SaveRemoteEntry([]uint{1}, model.RemoteEntry{Integration: "redmine", IssueId: "1", RemoteId: "42"}))
+		must(t, repo.SetTaskTags(1, []string{"review"}))
+		must(t, repo.SaveTaskNotes(1, "checked the logs"))
+		must(t, repo.DeleteTask(1))
+
+		tasks, err := repo.GetTasksByDate(contractStart)
+		must(t, err)
+		if ids := taskIds(tasks); !slices.Equal(ids, []uint{2}) {
+			t.Errorf("expected the deleted task to be left out, got %v", ids)
+		}
+		expectDuration(t, "daily", 3600)(repo.GetWorkedDurationForDate(contractStart, types.All))
+		expectDuration(t, "weekly", 3600)(repo.GetWeeklyWorkedDurationForDate(contractStart))
+
+		trash, err := repo.GetTrash()
+		must(t, err)
+		if len(trash) != 1 || trash[0].TaskId != 1 {
+			t.Fatalf("expected task 1 in the trash, got %+v", trash)
+		}
+
+		must(t, repo.RestoreFromTrash(trash[0].ID))
+		restored, err := repo.GetTask(1)
+		must(t, err)
+		if restored.Desc != "reported" || !restored.Reported || restored.End == nil {
+			t.Errorf("expected the reported task back, got %+v", restored)
+		}
+		expectTaskData(t, repo, 1, "review", "checked the logs", "42")
+		if err := repo.RestoreFromTrash(trash[0].ID); err == nil {
+			t.Error("expected an error restoring a task twice")
+		}
+
+		// a new task does not take the id of a task in the trash
+		must(t, repo.DeleteTask(2))
+		must(t, repo.CreateTask("new", nil, nil, nil))
+		must(t, repo.CloseOpenedTasks())
+		tasks, err = repo.GetTasksBetween(contractStart, clock.Now())
+		must(t, err)
+		if ids := taskIds(tasks); !slices.Equal(ids, []uint{3, 1}) {
+			t.Errorf("expected the new task to take a new id, got %v", ids)
+		}
+
+		// a task whose id has been used again is restored with a new one
+		must(t, repo.DeleteTask(1))
+		must(t, repo.UpdateTask(&model.Task{ID: 1, Desc: "reused", Start: model.LocalTimestamp{Time: clock.Now()}}))
+		trash, err = repo.GetTrash()
+		must(t, err)
+		must(t, repo.RestoreFromTrash(trash[0].ID))
+		tasks, err = repo.GetTasksBetween(contractStart, clock.Now())
+		must(t, err)
+		if ids := taskIds(tasks); !slices.Equal(ids, []uint{1, 3, 4}) || tasks[2].Desc != "reported" {
+			t.Fatalf("expected the task restored as 4, got %+v", tasks)
+		}
+		expectTaskData(t, repo, 4, "review", "checked the logs", "42")
+
+		must(t, repo.DeleteTask(3))
+		clock.Advance(10 * 24 * time.Hour)
+		must(t, repo.DeleteTask(4))
+
+		purged, err := repo.PurgeTrash(clock.Now().AddDate(0, 0, -5))
+		must(t, err)
+		trash, err = repo.GetTrash()
+		must(t, err)
+		if purged != 2 || len(trash) != 1 || trash[0].TaskId != 4 {
+			t.Errorf("expected only the tasks deleted 10 days ago to be purged, got %d and %+v", purged, trash)
+		}
+
+		must(t, repo.PurgeTrashEntry(trash[0].ID))
+		if _, err := repo.GetRemoteEntry(4); err == nil {
+			t.Error("expected the purged task to be unlinked from its remote entry")
+		}
+	})
+
+	t.Run("Projects", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		name := "mytime"
+		must(t, repo.CreateTask("coding", &name, nil, nil))
+		must(t, repo.SaveProject(&model.Project{Name: "zeta"}))
+		project := &model.Project{Name: name, Color: "green"}
+		must(t, repo.SaveProject(project))
+
+		if err := repo.SaveProject(&model.Project{Name: "zeta"}); err == nil {
+			t.Error("expected an error saving a duplicated project")
+		}
+
+		project.Name = "my-time"
+		must(t, repo.SaveProject(project))
+
+		projects, err := repo.GetProjects()
+		must(t, err)
+		if len(projects) != 2 || projects[0].Name != "my-time" || projects[0].Color != "green" {
+			t.Fatalf("expected the renamed project first, got %+v", projects)
+		}
+		if _, err := repo.GetProject(name); err == nil {
+			t.Error("expected the old name to be missing")
+		}
+
+		task, err := repo.GetTask(1)
+		must(t, err)
+		if task.ProjectName() != "my-time" {
+			t.Errorf("expected the task to follow the rename, got %q", task.ProjectName())
+		}
+		history, err := repo.GetTaskHistory(1)
+		must(t, err)
+		if len(history) != 2 || history[1].Action != model.HISTORY_UPDATE {
+			t.Errorf("expected the rename in the task history, got %+v", history)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		must(t, repo.CreateTask("standup", nil, nil, []string{"meeting", "daily"}))
+		must(t, repo.CreateTask("coding", nil, nil, nil))
+		must(t, repo.SetTaskTags(2, []string{"review"}))
+
+		tags, err := repo.GetTaskTags([]uint{1, 2, 3})
+		must(t, err)
+		if !slices.Equal(tags[1], []string{"daily", "meeting"}) || !slices.Equal(tags[2], []string{"review"}) || len(tags) != 2 {
+			t.Fatalf("expected the tags of both tasks, got %v", tags)
+		}
+
+		must(t, repo.SetTaskTags(1, []string{"meeting"}))
+		must(t, repo.DeleteTask(1))
+		must(t, repo.RestoreFromTrash(1))
+		tags, err = repo.GetTaskTags([]uint{1})
+		must(t, err)
+		if !slices.Equal(tags[1], []string{"meeting"}) {
+			t.Errorf("expected the restored task to keep its tags, got %v", tags)
+		}
+
+		must(t, repo.DeleteTask(1))
+		_, err = repo.PurgeTrash(clock.Now().Add(time.Hour))
+		must(t, err)
+		tags, err = repo.GetTaskTags([]uint{1, 2})
+		must(t, err)
+		if _, ok := tags[1]; ok || len(tags[2]) != 1 {
+			t.Errorf("expected the tags of the purged task to be removed, got %v", tags)
+		}
+
+		all, err := repo.GetTags()
+		must(t, err)
+		var names []string
+		for _, tag := range all {
+			names = append(names, tag.Name)
+		}
+		if !slices.Equal(names, []string{"daily", "meeting", "review"}) {
+			t.Errorf("expected every tag used, got %v", names)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		project, issue := "MyTime", "ISSUE-12"
+		for i, desc := range []string{"Review of the 50% discount", "Deploy", "review deploy"} {
+			clock.Set(contractStart.AddDate(0, 0, i))
+			must(t, repo.CreateTask(desc, &project, &issue, nil))
+			clock.Advance(time.Hour)
+			must(t, repo.CloseOpenedTasks())
+		}
+		renamed, err := repo.GetTask(2)
+		must(t, err)
+		renamed.Desc = "Release"
+		must(t, repo.UpdateTask(renamed))
+
+		search := func(query string, dateRange types.DateRange, limit int) []uint {
+			t.Helper()
+			tasks, err := repo.SearchTasks(query, dateRange, limit)
+			must(t, err)
+			return taskIds(tasks)
+		}
+
+		if ids := search("REV", types.DateRange{}, 0); !slices.Equal(ids, []uint{3, 1}) {
+			t.Errorf("expected the most recent review first, got %v", ids)
+		}
+		if ids := search("review deploy", types.DateRange{}, 0); !slices.Equal(ids, []uint{3}) {
+			t.Errorf("expected the task with both words, got %v", ids)
+		}
+		if ids := search("deploy", types.DateRange{}, 0); !slices.Equal(ids, []uint{3}) {
+			t.Errorf("expected the renamed task to be left out, got %v", ids)
+		}
+		if ids := search("mytime issue-12", types.DateRange{To: contractStart.AddDate(0, 0, 1)}, 1); !slices.Equal(ids, []uint{2}) {
+			t.Errorf("expected the project and external id to be searched within the range, got %v", ids)
+		}
+		if ids := search("50%", types.DateRange{From: contractStart}, 0); !slices.Equal(ids, []uint{1}) {
+			t.Errorf("expected the special characters to be searched as text, got %v", ids)
+		}
+		if ids := search("  ", types.DateRange{}, 0); len(ids) != 0 {
+			t.Errorf("expected nothing for an empty query, got %v", ids)
+		}
+
+		tasks, err := repo.SearchTasks("release", types.DateRange{}, 0)
+		must(t, err)
+		if len(tasks) != 1 || tasks[0].Duration != 3600 {
+			t.Errorf("expected the duration of the task found, got %+v", tasks)
+		}
+	})
+
+	t.Run("Notes", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		must(t, repo.CreateTask("investigation", nil, nil, nil))
+		must(t, repo.CreateTask("coding", nil, nil, nil))
+		must(t, repo.SaveTaskNotes(1, "# Findings\n\n- the cache is stale"))
+		must(t, repo.SaveTaskNotes(2, "first"))
+		must(t, repo.SaveTaskNotes(2, "second"))
+
+		notes, err := repo.GetTaskNotes([]uint{1, 2, 3})
+		must(t, err)
+		if len(notes) != 2 || notes[1] != "# Findings\n\n- the cache is stale" || notes[2] != "second" {
+			t.Fatalf("expected the notes of both tasks, got %v", notes)
+		}
+
+		if err := repo.SaveTaskNotes(99, "orphan"); err == nil {
+			t.Error("expected an error saving the notes of a missing task")
+		}
+
+		must(t, repo.SaveTaskNotes(2, "  "))
+		must(t, repo.DeleteTask(1))
+		_, err = repo.PurgeTrash(clock.Now().Add(time.Hour))
+		must(t, err)
+		notes, err = repo.GetTaskNotes([]uint{1, 2})
+		must(t, err)
+		if len(notes) != 0 {
+			t.Errorf("expected the emptied and the purged notes to be removed, got %v", notes)
+		}
+	})
+
+	t.Run("RemoteEntries", func(t *testing.T) {
+		clock := NewFakeClock(contractStart)
+		repo := newRepository(t, clock.Now)
+
+		for range 3 {
+			must(t, repo.CreateTask("review", nil, nil, nil))
+			clock.Advance(time.Hour)
+		}
+		must(t, repo.CloseOpenedTasks())
+
+		// the same id in two instances is two time entries
+		must(t, repo.SaveRemoteEntry([]uint{1, 2}, model.RemoteEntry{Integration: "redmine", Instance: "acme", IssueId: "123", RemoteId: "42"}))
+		must(t, repo.SaveRemoteEntry([]uint{3}, model.RemoteEntry{Integration: "redmine", IssueId: "123", RemoteId: "42"}))
+
+		entry, err := repo.GetRemoteEntry(1)
+		must(t, err)
+		if entry.Instance != "acme" || entry.IssueId != "123" || entry.RemoteId != "42" {
+			t.Fatalf("unexpected remote entry %+v", entry)
+		}
+		tasks, err := repo.GetRemoteEntryTasks(entry)
+		must(t, err)
+		if ids := taskIds(tasks); !slices.Equal(ids, []uint{2, 1}) {
+			t.Errorf("expected the tasks of the acme entry, got %v", ids)
+		}
+
+		must(t, repo.MoveRemoteEntry(entry, "acme", "456"))
+		moved, err := repo.GetRemoteEntry(2)
+		must(t, err)
+		other, err := repo.GetRemoteEntry(3)
+		must(t, err)
+		if moved.IssueId != "456" || other.IssueId != "123" {
+			t.Errorf("expected only the acme entry moved, got %+v and %+v", moved, other)
+		}
+
+		must(t, repo.DeleteRemoteEntry(moved))
+		if _, err := repo.GetRemoteEntry(1); err == nil {
+			t.Error("expected the acme entry to be deleted")
+		}
+		if _, err := repo.GetRemoteEntry(3); err != nil {
+			t.Errorf("expected the other entry to be kept, got %v", err)
+		}
+	})
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// mustOpen fails the test when the repository can not be opened.
+func mustOpen(t *testing.T) func(*GormRepository, error) *GormRepository {
+	return func(repo *GormRepository, err error) *GormRepository {
+		t.Helper()
+		must(t, err)
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	}
+}
+
+func expectDuration(t *testing.T, name string, expected int) func(int, error) {
+	return func(duration int, err error) {
+		t.Helper()
+		must(t, err)
+		if duration != expected {
+			t.Errorf("%s: expected %d, got %d", name, expected, duration)
+		}
+	}
+}
+
+// expectTaskData checks the tags, the notes and the remote entry of a task.
+func expectTaskData(t *testing.T, repo Repository, id uint, tag, notes, remoteId string) {
+	t.Helper()
+	tags, err := repo.GetTaskTags([]uint{id})
+	must(t, err)
+	taskNotes, err := repo.GetTaskNotes([]uint{id})
+	must(t, err)
+	entry, err := repo.GetRemoteEntry(id)
+	must(t, err)
+	if !slices.Equal(tags[id], []string{tag}) || taskNotes[id] != notes || entry.RemoteId != remoteId {
+		t.Errorf("task %d: expected %s, %q and %s, got %v, %q and %+v", id, tag, notes, remoteId, tags[id], taskNotes[id], entry)
+	}
+}
+
+func expectTime(t *testing.T, name string, timestamp *model.LocalTimestamp, expected string) {
+	t.Helper()
+	if timestamp == nil {
+		t.Errorf("%s: expected %s, got nil", name, expected)
+		return
+	}
+	if got := timestamp.Format(time.TimeOnly); got != expected {
+		t.Errorf("%s: expected %s, got %s", name, expected, got)
+	}
+}
+
+func taskIds(tasks []model.Task) []uint {
+	var ids []uint
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
@@ -0,0 +1,847 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/util"
+	"gorm.io/gorm"
+)
+
+const ORDER = "start DESC, id"
+
+// dialect holds the SQL that differs between the supported databases.
+type dialect struct {
+	// duration is the seconds between the start and the end of a task, or
+	// now, bound as its only parameter, when the task is still open.
+	duration string
+	// day formats a timestamp column as a YYYY-MM-DD text.
+	day func(column string) string
+	// concat joins the values of a column in a group with the separator.
+	concat func(column, separator string) string
+}
+
+// GormRepository implements the Repository on top of gorm, for any of the
+// supported databases.
+type GormRepository struct {
+	db      *gorm.DB
+	dialect dialect
+	clock   Clock
+	// fts tells whether the tasks_fts index of SQLite can be searched
+	fts bool
+}
+
+func newGormRepository(dialector gorm.Dialector, dialect dialect) (*GormRepository, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &GormRepository{db: db, dialect: dialect, clock: time.Now}
+	if err := migrate(db, time.Now); err != nil {
+		repo.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (r *GormRepository) Close() error {
+	db, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (r *GormRepository) GetTasksByDate(date time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.
+		Select(fmt.Sprintf("*, %s AS duration", r.dialect.duration), r.now()).
+		Where(r.dialect.day("start")+" = ?", date.Format(time.DateOnly)).
+		Order(ORDER).
+		Find(&tasks).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *GormRepository) GetTasksBetween(from, to time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.
+		Select(fmt.Sprintf("*, %s AS duration", r.dialect.duration), r.now()).
+		Where(r.dialect.day("start")+" BETWEEN ? AND ?", from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order(ORDER).
+		Find(&tasks).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// SearchTasks returns the most recent tasks, first, whose description,
+// project or external id match every word of the query.
+func (r *GormRepository) SearchTasks(query string, dateRange types.DateRange, limit int) ([]model.Task, error) {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	db := r.db.Select(fmt.Sprintf("tasks.*, %s AS duration", r.dialect.duration), r.now())
+
+	if r.fts {
+		if err := refreshSearchIndex(r.db); err != nil {
+			return nil, err
+		}
+		db = db.
+			Joins("JOIN tasks_fts ON tasks_fts.rowid = tasks.id").
+			Where("tasks_fts MATCH ?", ftsQuery(words))
+	} else {
+		for _, word := range words {
+			pattern := likePattern(word)
+			db = db.Where(
+				`(LOWER("desc") LIKE ? ESCAPE '\' OR LOWER(COALESCE(project, '')) LIKE ? ESCAPE '\' OR LOWER(COALESCE(external_id, '')) LIKE ? ESCAPE '\')`,
+				pattern, pattern, pattern,
+			)
+		}
+	}
+
+	if !dateRange.From.IsZero() {
+		db = db.Where(r.dialect.day("start")+" >= ?", dateRange.From.Format(time.DateOnly))
+	}
+	if !dateRange.To.IsZero() {
+		db = db.Where(r.dialect.day("start")+" <= ?", dateRange.To.Format(time.DateOnly))
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var tasks []model.Task
+	if err := db.Order("start DESC, tasks.id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// RebuildSearchIndex creates the tasks_fts index of SQLite again, failing when
+// the library has no FTS5.
+func (r *GormRepository) RebuildSearchIndex() error {
+	if r.db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		return createSearchIndex(tx, true)
+	}); err != nil {
+		return err
+	}
+	r.fts = true
+	return nil
+}
+
+func (r *GormRepository) GetWorkedDurationForDate(date time.Time, status types.TaskStatus) (int, error) {
+	var result int
+	query := r.db.
+		Model(&model.Task{}).
+		Select(fmt.Sprintf("COALESCE(SUM(%s), 0) AS duration", r.dialect.duration), r.now()).
+		Where(r.dialect.day("start")+" = ?", date.Format(time.DateOnly))
+
+	if status == types.TaskStatus(types.Reported) {
+		query = query.Where("reported = ?", true)
+	} else if status == types.TaskStatus(types.NotReported) {
+		query = query.Where("reported = ?", false)
+	}
+
+	err := query.Find(&result).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (r *GormRepository) GetWeeklyWorkedDurationForDate(date time.Time) (int, error) {
+	var result int
+	monday, sunday := util.WeekBounds(date)
+	err := r.db.
+		Model(&model.Task{}).
+		Select(fmt.Sprintf("COALESCE(SUM(%s), 0) AS duration", r.dialect.duration), r.now()).
+		Where(r.dialect.day("start")+" BETWEEN ? AND ?", monday.Format(time.DateOnly), sunday.Format(time.DateOnly)).
+		Find(&result).
+		Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result, nil
+}
+
+func (r *GormRepository) GetSettings() (*model.Settings, error) {
+	var settings model.Settings
+	if err := r.db.First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *GormRepository) CreateTask(description string, project, externalId *string, tags []string) error {
+	newTask := model.Task{
+		Project:    project,
+		Desc:       description,
+		ExternalId: externalId,
+		Start:      r.now(),
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createTask(tx, &newTask); err != nil {
+			return err
+		}
+		if err := setTaskTags(tx, newTask.ID, tags); err != nil {
+			return err
+		}
+		// the id of a purged task may be used again
+		if err := tx.Where("task_id = ?", newTask.ID).Delete(&model.TaskNote{}).Error; err != nil {
+			return err
+		}
+		return r.record(tx, model.HISTORY_CREATE, newTask.ID, nil, &newTask)
+	})
+}
+
+func (r *GormRepository) CloseOpenedTasks() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tasks []model.Task
+		if err := tx.Where(`"end" IS NULL`).Find(&tasks).Error; err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			before := task
+			task.End = &model.LocalTimestamp{Time: r.clock()}
+			if err := tx.Save(&task).Error; err != nil {
+				return err
+			}
+			if err := r.record(tx, model.HISTORY_UPDATE, task.ID, &before, &task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormRepository) CloseTask(id uint) error {
+	var task model.Task
+	err := r.db.First(&task, id).Error
+
+	if err != nil {
+		return err
+	}
+
+	if task.End != nil {
+		return fmt.Errorf("task already closed")
+	}
+
+	before := task
+	task.End = &model.LocalTimestamp{Time: r.clock()}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		return r.record(tx, model.HISTORY_UPDATE, task.ID, &before, &task)
+	})
+}
+
+func (r *GormRepository) GetTask(id uint) (*model.Task, error) {
+	var task model.Task
+	err := r.db.First(&task, id).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (r *GormRepository) UpdateTask(task *model.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := findTask(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Save(task).Error; err != nil {
+			return err
+		}
+
+		action := model.HISTORY_UPDATE
+		if before == nil {
+			action = model.HISTORY_CREATE
+		}
+		return r.record(tx, action, task.ID, before, task)
+	})
+}
+
+// RestoreTask brings a task back to a snapshot taken from its history,
+// moving it to the trash when the snapshot is nil.
+func (r *GormRepository) RestoreTask(id uint, snapshot *model.Task, action string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		before, err := findTask(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if snapshot == nil {
+			if before == nil {
+				return nil
+			}
+			if err := r.trash(tx, before); err != nil {
+				return err
+			}
+		} else {
+			task := *snapshot
+			task.ID = id
+			if err := tx.Save(&task).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id = ?", id).Delete(&model.TrashEntry{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return r.record(tx, action, id, before, snapshot)
+	})
+}
+
+func (r *GormRepository) DeleteTask(id uint) error {
+	var task model.Task
+	err := r.db.First(&task, id).Error
+
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.trash(tx, &task); err != nil {
+			return err
+		}
+		return r.record(tx, model.HISTORY_DELETE, id, &task, nil)
+	})
+}
+
+func (r *GormRepository) GetTasksToSync() ([]types.TasksToSync, error) {
+	var result []types.TasksToSync
+
+	day := r.dialect.day("start")
+
+	err := r.db.
+		Model(&model.Task{}).
+		Select(fmt.Sprintf("%s AS id, "+
+			"external_id, "+
+			"SUM(%s) AS duration, "+
+			`"desc", `+
+			"%s AS date, "+
+			"project, "+
+			"%s AS ids", r.dialect.concat("id", "-"), r.dialect.duration, day, r.dialect.concat("id", ",")), r.now()).
+		Where(`"end" IS NOT NULL AND reported = false AND external_id IS NOT NULL AND external_id != ''`).
+		Group(fmt.Sprintf(`external_id, "desc", %s, project`, day)).
+		Order(day + " DESC, MIN(id)").
+		Find(&result).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *GormRepository) SetTaskAsReported(id uint) error {
+	var task model.Task
+	err := r.db.First(&task, id).Error
+
+	if err != nil {
+		return err
+	}
+
+	before := task
+	task.Reported = true
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		return r.record(tx, model.HISTORY_REPORT, task.ID, &before, &task)
+	})
+}
+
+func (r *GormRepository) GetCacheEntry(key string) (*model.CacheEntry, error) {
+	var entry model.CacheEntry
+	result := r.db.Where("key = ?", key).Limit(1).Find(&entry)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &entry, nil
+}
+
+func (r *GormRepository) SaveCacheEntry(key, value string) error {
+	entry := model.CacheEntry{
+		Key:       key,
+		Value:     value,
+		UpdatedAt: r.now(),
+	}
+
+	if err := r.db.Save(&entry).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *GormRepository) SaveRemoteEntry(taskIds []uint, entry model.RemoteEntry) error {
+	now := r.now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, taskId := range taskIds {
+			entry := model.RemoteEntry{
+				TaskId:      taskId,
+				Integration: entry.Integration,
+				Instance:    entry.Instance,
+				IssueId:     entry.IssueId,
+				RemoteId:    entry.RemoteId,
+				CreatedAt:   now,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormRepository) GetRemoteEntry(taskId uint) (*model.RemoteEntry, error) {
+	var entry model.RemoteEntry
+	result := r.db.Where("task_id = ?", taskId).Order("id DESC").Limit(1).Find(&entry)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &entry, nil
+}
+
+func (r *GormRepository) GetRemoteEntryTasks(entry *model.RemoteEntry) ([]model.Task, error) {
+	var tasks []model.Task
+	err := r.db.
+		Select(fmt.Sprintf("*, %s AS duration", r.dialect.duration), r.now()).
+		Where("id IN (?)", r.db.
+			Model(&model.RemoteEntry{}).
+			Select("task_id").
+			Where("integration = ? AND instance = ? AND remote_id = ?", entry.Integration, entry.Instance, entry.RemoteId)).
+		Order(ORDER).
+		Find(&tasks).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// MoveRemoteEntry records that the remote entry is now in another issue.
+func (r *GormRepository) MoveRemoteEntry(entry *model.RemoteEntry, instance, issueId string) error {
+	return r.db.
+		Model(&model.RemoteEntry{}).
+		Where("integration = ? AND instance = ? AND remote_id = ?", entry.Integration, entry.Instance, entry.RemoteId).
+		Updates(map[string]any{"instance": instance, "issue_id": issueId}).
+		Error
+}
+
+func (r *GormRepository) DeleteRemoteEntry(entry *model.RemoteEntry) error {
+	return r.db.
+		Where("integration = ? AND instance = ? AND remote_id = ?", entry.Integration, entry.Instance, entry.RemoteId).
+		Delete(&model.RemoteEntry{}).
+		Error
+}
+
+func (r *GormRepository) GetOutboxEntries() ([]model.OutboxEntry, error) {
+	var entries []model.OutboxEntry
+	if err := r.db.Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GormRepository) SaveOutboxEntry(entry *model.OutboxEntry) error {
+	now := r.now()
+	if entry.ID == 0 {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+
+	return r.db.Save(entry).Error
+}
+
+func (r *GormRepository) DeleteOutboxEntry(id uint) error {
+	return r.db.Delete(&model.OutboxEntry{}, id).Error
+}
+
+func (r *GormRepository) now() model.LocalTimestamp {
+	return model.LocalTimestamp{Time: r.clock()}
+}
+
+func (r *GormRepository) GetTaskHistory(taskId uint) ([]model.TaskHistory, error) {
+	var entries []model.TaskHistory
+	if err := r.db.Where("task_id = ?", taskId).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GormRepository) GetHistorySince(id uint) ([]model.TaskHistory, error) {
+	var entries []model.TaskHistory
+	if err := r.db.Where("id > ?", id).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *GormRepository) GetLastHistoryId() (uint, error) {
+	var id uint
+	err := r.db.Model(&model.TaskHistory{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+func (r *GormRepository) GetTrash() ([]model.TrashEntry, error) {
+	var entries []model.TrashEntry
+	if err := r.db.Order("deleted_at DESC, id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RestoreFromTrash puts a deleted task back, with its id, or with a new one
+// when its id has been used again, carrying over its tags, notes and remote
+// entries.
+func (r *GormRepository) RestoreFromTrash(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var entry model.TrashEntry
+		if err := tx.First(&entry, id).Error; err != nil {
+			return err
+		}
+
+		task, err := ParseTaskSnapshot(entry.Task)
+		if err != nil {
+			return err
+		}
+
+		existing, err := findTask(tx, entry.TaskId)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			task.ID = entry.TaskId
+			err = tx.Save(task).Error
+		} else {
+			task.ID = 0
+			err = createTask(tx, task)
+		}
+		if err != nil {
+			return err
+		}
+		if task.ID != entry.TaskId {
+			if err := moveTaskData(tx, entry.TaskId, task.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&entry).Error; err != nil {
+			return err
+		}
+		return r.record(tx, model.HISTORY_RESTORE, task.ID, nil, task)
+	})
+}
+
+func (r *GormRepository) PurgeTrashEntry(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := purgeTaskData(tx, tx.Model(&model.TrashEntry{}).Select("task_id").Where("id = ?", id)); err != nil {
+			return err
+		}
+		return tx.Delete(&model.TrashEntry{}, id).Error
+	})
+}
+
+// PurgeTrash removes the tasks deleted before the given time, returning how
+// many were removed.
+func (r *GormRepository) PurgeTrash(before time.Time) (int, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		limit := model.LocalTimestamp{Time: before}
+		if err := purgeTaskData(tx, tx.Model(&model.TrashEntry{}).Select("task_id").Where("deleted_at < ?", limit)); err != nil {
+			return err
+		}
+		result := tx.Where("deleted_at < ?", limit).Delete(&model.TrashEntry{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return int(purged), err
+}
+
+func (r *GormRepository) GetTags() ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := r.db.Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTaskTags returns the tag names of the given tasks, sorted, by task id.
+func (r *GormRepository) GetTaskTags(taskIds []uint) (map[uint][]string, error) {
+	var rows []struct {
+		TaskId uint
+		Name   string
+	}
+	err := r.db.
+		Model(&model.TaskTag{}).
+		Select("task_tags.task_id, tags.name").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id IN ?", taskIds).
+		Order("tags.name").
+		Find(&rows).
+		Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[uint][]string{}
+	for _, row := range rows {
+		result[row.TaskId] = append(result[row.TaskId], row.Name)
+	}
+	return result, nil
+}
+
+func (r *GormRepository) SetTaskTags(taskId uint, tags []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setTaskTags(tx, taskId, tags)
+	})
+}
+
+func (r *GormRepository) GetProjects() ([]model.Project, error) {
+	var projects []model.Project
+	if err := r.db.Order("name").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *GormRepository) GetProject(name string) (*model.Project, error) {
+	var project model.Project
+	result := r.db.Where("name = ?", name).Limit(1).Find(&project)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &project, nil
+}
+
+// SaveProject creates or updates a project. Renaming it renames the project
+// of its tasks too.
+func (r *GormRepository) SaveProject(project *model.Project) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&model.Project{}).Where("name = ? AND id != ?", project.Name, project.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return fmt.Errorf("project %s already exists", project.Name)
+		}
+
+		if project.ID != 0 {
+			var previous model.Project
+			if err := tx.First(&previous, project.ID).Error; err != nil {
+				return err
+			}
+			if previous.Name != project.Name {
+				if err := r.renameProject(tx, previous.Name, project.Name); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Save(project).Error
+	})
+}
+
+func (r *GormRepository) renameProject(tx *gorm.DB, from, to string) error {
+	var tasks []model.Task
+	if err := tx.Where("project = ?", from).Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		before := task
+		task.Project = &to
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		if err := r.record(tx, model.HISTORY_UPDATE, task.ID, &before, &task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trash moves a task to the trash. Its tags, notes and remote entries are kept
+// until it is purged, so it is restored with them.
+func (r *GormRepository) trash(tx *gorm.DB, task *model.Task) error {
+	content, err := snapshot(task)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Delete(task).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.TrashEntry{TaskId: task.ID, Task: content, DeletedAt: r.now()}).Error
+}
+
+// record adds a change of a task to its history, within the transaction of
+// the change.
+func (r *GormRepository) record(tx *gorm.DB, action string, taskId uint, before, after *model.Task) error {
+	entry, err := newHistoryEntry(action, taskId, before, after)
+	if err != nil {
+		return err
+	}
+	entry.CreatedAt = r.now()
+	return tx.Create(&entry).Error
+}
+
+// GetTaskNotes returns the notes of the given tasks having any, by task id.
+func (r *GormRepository) GetTaskNotes(taskIds []uint) (map[uint]string, error) {
+	var notes []model.TaskNote
+	if err := r.db.Where("task_id IN ?", taskIds).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+
+	result := map[uint]string{}
+	for _, note := range notes {
+		result[note.TaskId] = note.Notes
+	}
+	return result, nil
+}
+
+// SaveTaskNotes replaces the notes of a task, removing them when empty.
+func (r *GormRepository) SaveTaskNotes(taskId uint, notes string) error {
+	if strings.TrimSpace(notes) == "" {
+		return r.db.Where("task_id = ?", taskId).Delete(&model.TaskNote{}).Error
+	}
+
+	task, err := findTask(r.db, taskId)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	return r.db.Save(&model.TaskNote{TaskId: taskId, Notes: notes, UpdatedAt: r.now()}).Error
+}
+
+// setTaskTags replaces the tags of a task, creating the ones missing.
+func setTaskTags(tx *gorm.DB, taskId uint, tags []string) error {
+	if err := tx.Where("task_id = ?", taskId).Delete(&model.TaskTag{}).Error; err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tag := model.Tag{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.TaskTag{TaskId: taskId, TagId: tag.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskData returns the models keeping data of the tasks by their id.
+func taskData() []any {
+	return []any{&model.TaskTag{}, &model.TaskNote{}, &model.RemoteEntry{}}
+}
+
+// createTask inserts a new task. SQLite gives the highest id again once its
+// task is deleted, which would hand the new task the data kept for the one in
+// the trash, so the id is set above both there. PostgreSQL never reuses the
+// ids of its sequence.
+func createTask(tx *gorm.DB, task *model.Task) error {
+	if tx.Dialector.Name() == "sqlite" {
+		err := tx.
+			Raw("SELECT MAX(id) + 1 FROM (SELECT COALESCE(MAX(id), 0) AS id FROM tasks UNION ALL SELECT COALESCE(MAX(task_id), 0) FROM trash)").
+			Scan(&task.ID).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Create(task).Error
+}
+
+// moveTaskData gives the tags, notes and remote entries of a task to another.
+func moveTaskData(tx *gorm.DB, from, to uint) error {
+	for _, data := range taskData() {
+		if err := tx.Model(data).Where("task_id = ?", from).Update("task_id", to).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeTaskData removes the tags, the notes and the remote entries of the
+// purged tasks, unless their id is used again by a task.
+func purgeTaskData(tx *gorm.DB, taskIds *gorm.DB) error {
+	for _, data := range taskData() {
+		err := tx.
+			Where("task_id IN (?)", taskIds).
+			Where("task_id NOT IN (?)", tx.Model(&model.Task{}).Select("id")).
+			Delete(data).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findTask returns the task of the id, or nil when there is none.
+func findTask(tx *gorm.DB, id uint) (*model.Task, error) {
+	var task model.Task
+	result := tx.Where("id = ?", id).Limit(1).Find(&task)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &task, nil
+}
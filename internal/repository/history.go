@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/francescarpi/mytime/internal/model"
+)
+
+func newHistoryEntry(action string, taskId uint, before, after *model.Task) (model.TaskHistory, error) {
+	entry := model.TaskHistory{TaskId: taskId, Action: action}
+
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return entry, err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+func snapshot(task *model.Task) (string, error) {
+	if task == nil {
+		return "", nil
+	}
+
+	saved := *task
+	saved.Duration = 0
+	content, err := json.Marshal(saved)
+	return string(content), err
+}
+
+// ParseTaskSnapshot decodes a task saved in the history or the trash. An
+// empty snapshot is a task that did not exist.
+func ParseTaskSnapshot(snapshot string) (*model.Task, error) {
+	if snapshot == "" {
+		return nil, nil
+	}
+
+	var task model.Task
+	if err := json.Unmarshal([]byte(snapshot), &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
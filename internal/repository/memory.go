@@ -0,0 +1,906 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/util"
+	"gorm.io/gorm"
+)
+
+// MemoryRepository keeps everything in memory, behaving as the SQL
+// repositories do. It is meant for the tests.
+type MemoryRepository struct {
+	mu       sync.Mutex
+	clock    Clock
+	settings *model.Settings
+	tasks    []model.Task
+	cache    map[string]model.CacheEntry
+	remote   []model.RemoteEntry
+	outbox   []model.OutboxEntry
+	history  []model.TaskHistory
+	trashed  []model.TrashEntry
+	projects []model.Project
+	tags     []model.Tag
+	taskTags map[uint][]string
+	notes    map[uint]string
+
+	// last ids of the tasks, remote entries, outbox, history, trash entries,
+	// projects and tags
+	lastTaskId, lastRemoteId, lastOutboxId, lastHistoryId, lastTrashId, lastProjectId, lastTagId uint
+}
+
+func NewMemoryRepository(clock Clock) *MemoryRepository {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	settings := model.DefaultSettings()
+	return &MemoryRepository{
+		clock:    clock,
+		settings: &settings,
+		cache:    map[string]model.CacheEntry{},
+		taskTags: map[uint][]string{},
+		notes:    map[uint]string{},
+	}
+}
+
+// SetSettings replaces the settings, which the Repository has no way to save.
+func (r *MemoryRepository) SetSettings(settings model.Settings) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settings = &settings
+}
+
+func (r *MemoryRepository) GetTasksByDate(date time.Time) ([]model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day := date.Format(time.DateOnly)
+	return r.findTasks(func(task model.Task) bool { return taskDay(task) == day }), nil
+}
+
+func (r *MemoryRepository) GetTasksBetween(from, to time.Time) ([]model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.findTasks(between(from, to)), nil
+}
+
+// RebuildSearchIndex does nothing, as the tasks are searched as they are.
+func (r *MemoryRepository) RebuildSearchIndex() error {
+	return nil
+}
+
+// SearchTasks returns the most recent tasks, first, whose description,
+// project or external id contain every word of the query.
+func (r *MemoryRepository) SearchTasks(query string, dateRange types.DateRange, limit int) ([]model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	words := searchWords(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	tasks := r.findTasks(func(task model.Task) bool {
+		day := taskDay(task)
+		if !dateRange.From.IsZero() && day < dateRange.From.Format(time.DateOnly) {
+			return false
+		}
+		if !dateRange.To.IsZero() && day > dateRange.To.Format(time.DateOnly) {
+			return false
+		}
+
+		text := strings.ToLower(task.Desc + "\n" + task.ProjectName())
+		if task.ExternalId != nil {
+			text += "\n" + strings.ToLower(*task.ExternalId)
+		}
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+		return true
+	})
+
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (r *MemoryRepository) GetWorkedDurationForDate(date time.Time, status types.TaskStatus) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day := date.Format(time.DateOnly)
+	return r.sumDuration(func(task model.Task) bool {
+		if taskDay(task) != day {
+			return false
+		}
+		switch status {
+		case types.Reported:
+			return task.Reported
+		case types.NotReported:
+			return !task.Reported
+		}
+		return true
+	}), nil
+}
+
+func (r *MemoryRepository) GetWeeklyWorkedDurationForDate(date time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sumDuration(between(util.WeekBounds(date))), nil
+}
+
+func (r *MemoryRepository) Close() error {
+	return nil
+}
+
+func (r *MemoryRepository) GetSettings() (*model.Settings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.settings == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	settings := *r.settings
+	return &settings, nil
+}
+
+func (r *MemoryRepository) CreateTask(description string, project, externalId *string, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task := model.Task{
+		Project:    project,
+		Desc:       description,
+		ExternalId: externalId,
+		Start:      r.now(),
+	}
+	task.ID = r.saveTask(task)
+	r.setTaskTags(task.ID, tags)
+	return r.record(model.HISTORY_CREATE, task.ID, nil, &task)
+}
+
+func (r *MemoryRepository) CloseOpenedTasks() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tasks {
+		if r.tasks[i].End == nil {
+			before := cloneTask(r.tasks[i])
+			end := r.now()
+			r.tasks[i].End = &end
+			if err := r.record(model.HISTORY_UPDATE, before.ID, &before, &r.tasks[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) CloseTask(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.taskIndex(id)
+	if i < 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if r.tasks[i].End != nil {
+		return fmt.Errorf("task already closed")
+	}
+
+	before := cloneTask(r.tasks[i])
+	end := r.now()
+	r.tasks[i].End = &end
+	return r.record(model.HISTORY_UPDATE, id, &before, &r.tasks[i])
+}
+
+func (r *MemoryRepository) GetTask(id uint) (*model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.taskIndex(id)
+	if i < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	task := cloneTask(r.tasks[i])
+	return &task, nil
+}
+
+func (r *MemoryRepository) UpdateTask(task *model.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	action := model.HISTORY_UPDATE
+	var before *model.Task
+	if i := r.taskIndex(task.ID); task.ID != 0 && i >= 0 {
+		saved := cloneTask(r.tasks[i])
+		before = &saved
+	} else {
+		action = model.HISTORY_CREATE
+	}
+
+	task.ID = r.saveTask(*task)
+	return r.record(action, task.ID, before, &r.tasks[r.taskIndex(task.ID)])
+}
+
+// RestoreTask brings a task back to a snapshot taken from its history,
+// moving it to the trash when the snapshot is nil.
+func (r *MemoryRepository) RestoreTask(id uint, snapshot *model.Task, action string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var before *model.Task
+	i := r.taskIndex(id)
+	if i >= 0 {
+		saved := cloneTask(r.tasks[i])
+		before = &saved
+	}
+
+	if snapshot == nil {
+		if before == nil {
+			return nil
+		}
+		if err := r.trash(i); err != nil {
+			return err
+		}
+		return r.record(action, id, before, nil)
+	}
+
+	task := cloneTask(*snapshot)
+	task.ID = id
+	r.saveTask(task)
+	r.trashed = slices.DeleteFunc(r.trashed, func(entry model.TrashEntry) bool { return entry.TaskId == id })
+	return r.record(action, id, before, &task)
+}
+
+func (r *MemoryRepository) DeleteTask(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.taskIndex(id)
+	if i < 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	before := r.tasks[i]
+	if err := r.trash(i); err != nil {
+		return err
+	}
+	return r.record(model.HISTORY_DELETE, id, &before, nil)
+}
+
+func (r *MemoryRepository) GetTasksToSync() ([]types.TasksToSync, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type groupKey struct {
+		externalId string
+		desc       string
+		day        string
+		project    string
+		hasProject bool
+	}
+
+	var keys []groupKey
+	groups := map[groupKey][]model.Task{}
+
+	for _, task := range r.tasks {
+		if task.End == nil || task.Reported || task.ExternalId == nil || *task.ExternalId == "" {
+			continue
+		}
+
+		key := groupKey{
+			externalId: *task.ExternalId,
+			desc:       task.Desc,
+			day:        taskDay(task),
+			project:    task.ProjectName(),
+			hasProject: task.Project != nil,
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], task)
+	}
+
+	slices.SortStableFunc(keys, func(a, b groupKey) int {
+		return cmp.Or(
+			strings.Compare(b.day, a.day),
+			cmp.Compare(groups[a][0].ID, groups[b][0].ID),
+		)
+	})
+
+	result := []types.TasksToSync{}
+	for _, key := range keys {
+		group := types.TasksToSync{
+			ExternalId: key.externalId,
+			Desc:       key.desc,
+			Date:       key.day,
+			Project:    key.project,
+		}
+		for _, task := range groups[key] {
+			group.Duration += r.duration(task)
+			group.Ids.IDs = append(group.Ids.IDs, strconv.Itoa(int(task.ID)))
+		}
+		group.Id = strings.Join(group.Ids.IDs, "-")
+		result = append(result, group)
+	}
+
+	return result, nil
+}
+
+func (r *MemoryRepository) SetTaskAsReported(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.taskIndex(id)
+	if i < 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	before := cloneTask(r.tasks[i])
+	r.tasks[i].Reported = true
+	return r.record(model.HISTORY_REPORT, id, &before, &r.tasks[i])
+}
+
+func (r *MemoryRepository) GetCacheEntry(key string) (*model.CacheEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.cache[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (r *MemoryRepository) SaveCacheEntry(key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cache[key] = model.CacheEntry{Key: key, Value: value, UpdatedAt: r.now()}
+	return nil
+}
+
+func (r *MemoryRepository) SaveRemoteEntry(taskIds []uint, entry model.RemoteEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, taskId := range taskIds {
+		r.lastRemoteId++
+		r.remote = append(r.remote, model.RemoteEntry{
+			ID:          r.lastRemoteId,
+			TaskId:      taskId,
+			Integration: entry.Integration,
+			Instance:    entry.Instance,
+			IssueId:     entry.IssueId,
+			RemoteId:    entry.RemoteId,
+			CreatedAt:   now,
+		})
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetRemoteEntry(taskId uint) (*model.RemoteEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.remote) - 1; i >= 0; i-- {
+		if r.remote[i].TaskId == taskId {
+			entry := r.remote[i]
+			return &entry, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) GetRemoteEntryTasks(entry *model.RemoteEntry) ([]model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var taskIds []uint
+	for _, remote := range r.remote {
+		if sameRemoteEntry(remote, entry) {
+			taskIds = append(taskIds, remote.TaskId)
+		}
+	}
+
+	return r.findTasks(func(task model.Task) bool { return slices.Contains(taskIds, task.ID) }), nil
+}
+
+func (r *MemoryRepository) MoveRemoteEntry(entry *model.RemoteEntry, instance, issueId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, remote := range r.remote {
+		if sameRemoteEntry(remote, entry) {
+			r.remote[i].Instance, r.remote[i].IssueId = instance, issueId
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) DeleteRemoteEntry(entry *model.RemoteEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remote = slices.DeleteFunc(r.remote, func(remote model.RemoteEntry) bool {
+		return sameRemoteEntry(remote, entry)
+	})
+	return nil
+}
+
+// sameRemoteEntry tells whether both rows link to the same time entry, as the
+// ids are only unique within an instance.
+func sameRemoteEntry(remote model.RemoteEntry, entry *model.RemoteEntry) bool {
+	return remote.Integration == entry.Integration && remote.Instance == entry.Instance && remote.RemoteId == entry.RemoteId
+}
+
+func (r *MemoryRepository) GetOutboxEntries() ([]model.OutboxEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.outbox), nil
+}
+
+func (r *MemoryRepository) SaveOutboxEntry(entry *model.OutboxEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	entry.UpdatedAt = now
+
+	i := slices.IndexFunc(r.outbox, func(saved model.OutboxEntry) bool { return saved.ID == entry.ID })
+	if entry.ID == 0 || i < 0 {
+		if entry.ID == 0 {
+			r.lastOutboxId++
+			entry.ID = r.lastOutboxId
+		}
+		entry.CreatedAt = now
+		r.outbox = append(r.outbox, *entry)
+		return nil
+	}
+
+	r.outbox[i] = *entry
+	return nil
+}
+
+func (r *MemoryRepository) DeleteOutboxEntry(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.outbox = slices.DeleteFunc(r.outbox, func(entry model.OutboxEntry) bool { return entry.ID == id })
+	return nil
+}
+
+func (r *MemoryRepository) GetTaskHistory(taskId uint) ([]model.TaskHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []model.TaskHistory
+	for _, entry := range r.history {
+		if entry.TaskId == taskId {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *MemoryRepository) GetHistorySince(id uint) ([]model.TaskHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []model.TaskHistory
+	for _, entry := range r.history {
+		if entry.ID > id {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *MemoryRepository) GetLastHistoryId() (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lastHistoryId, nil
+}
+
+func (r *MemoryRepository) GetTrash() ([]model.TrashEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := slices.Clone(r.trashed)
+	slices.SortStableFunc(entries, func(a, b model.TrashEntry) int {
+		return cmp.Or(b.DeletedAt.Compare(a.DeletedAt.Time), cmp.Compare(b.ID, a.ID))
+	})
+	return entries, nil
+}
+
+// RestoreFromTrash puts a deleted task back, with its id, or with a new one
+// when its id has been used again, carrying over its tags, notes and remote
+// entries.
+func (r *MemoryRepository) RestoreFromTrash(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.trashed, func(entry model.TrashEntry) bool { return entry.ID == id })
+	if i < 0 {
+		return gorm.ErrRecordNotFound
+	}
+	entry := r.trashed[i]
+
+	task, err := ParseTaskSnapshot(entry.Task)
+	if err != nil {
+		return err
+	}
+
+	task.ID = entry.TaskId
+	if r.taskIndex(entry.TaskId) >= 0 {
+		task.ID = 0
+	}
+	task.ID = r.saveTask(*task)
+	if task.ID != entry.TaskId {
+		r.moveTaskData(entry.TaskId, task.ID)
+	}
+	r.trashed = slices.Delete(r.trashed, i, i+1)
+	return r.record(model.HISTORY_RESTORE, task.ID, nil, task)
+}
+
+func (r *MemoryRepository) PurgeTrashEntry(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trashed = slices.DeleteFunc(r.trashed, func(entry model.TrashEntry) bool {
+		if entry.ID == id {
+			r.purgeTaskData(entry.TaskId)
+			return true
+		}
+		return false
+	})
+	return nil
+}
+
+// PurgeTrash removes the tasks deleted before the given time, returning how
+// many were removed.
+func (r *MemoryRepository) PurgeTrash(before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limit := stored(model.LocalTimestamp{Time: before})
+	count := len(r.trashed)
+	r.trashed = slices.DeleteFunc(r.trashed, func(entry model.TrashEntry) bool {
+		if entry.DeletedAt.Before(limit.Time) {
+			r.purgeTaskData(entry.TaskId)
+			return true
+		}
+		return false
+	})
+	return count - len(r.trashed), nil
+}
+
+func (r *MemoryRepository) GetProjects() ([]model.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projects := slices.Clone(r.projects)
+	slices.SortFunc(projects, func(a, b model.Project) int { return strings.Compare(a.Name, b.Name) })
+	return projects, nil
+}
+
+func (r *MemoryRepository) GetProject(name string) (*model.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.projects, func(project model.Project) bool { return project.Name == name })
+	if i < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	project := r.projects[i]
+	return &project, nil
+}
+
+// SaveProject creates or updates a project. Renaming it renames the project
+// of its tasks too.
+func (r *MemoryRepository) SaveProject(project *model.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.ContainsFunc(r.projects, func(saved model.Project) bool {
+		return saved.Name == project.Name && saved.ID != project.ID
+	}) {
+		return fmt.Errorf("project %s already exists", project.Name)
+	}
+
+	if project.ID == 0 {
+		r.lastProjectId++
+		project.ID = r.lastProjectId
+		r.projects = append(r.projects, *project)
+		return nil
+	}
+
+	i := slices.IndexFunc(r.projects, func(saved model.Project) bool { return saved.ID == project.ID })
+	if i < 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if previous := r.projects[i].Name; previous != project.Name {
+		for j := range r.tasks {
+			if r.tasks[j].Project != nil && *r.tasks[j].Project == previous {
+				before := cloneTask(r.tasks[j])
+				name := project.Name
+				r.tasks[j].Project = &name
+				if err := r.record(model.HISTORY_UPDATE, before.ID, &before, &r.tasks[j]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	r.projects[i] = *project
+	return nil
+}
+
+func (r *MemoryRepository) GetTags() ([]model.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tags := slices.Clone(r.tags)
+	slices.SortFunc(tags, func(a, b model.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return tags, nil
+}
+
+// GetTaskTags returns the tag names of the given tasks, sorted, by task id.
+func (r *MemoryRepository) GetTaskTags(taskIds []uint) (map[uint][]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := map[uint][]string{}
+	for _, id := range taskIds {
+		if tags, ok := r.taskTags[id]; ok {
+			result[id] = slices.Clone(tags)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) SetTaskTags(taskId uint, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setTaskTags(taskId, tags)
+	return nil
+}
+
+// setTaskTags replaces the tags of a task, creating the ones missing.
+func (r *MemoryRepository) setTaskTags(taskId uint, tags []string) {
+	delete(r.taskTags, taskId)
+	if len(tags) == 0 {
+		return
+	}
+
+	for _, name := range tags {
+		if !slices.ContainsFunc(r.tags, func(tag model.Tag) bool { return tag.Name == name }) {
+			r.lastTagId++
+			r.tags = append(r.tags, model.Tag{ID: r.lastTagId, Name: name})
+		}
+	}
+
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	r.taskTags[taskId] = tags
+}
+
+// purgeTaskData removes the tags, the notes and the remote entries of a
+// purged task, unless its id is used again by a task.
+func (r *MemoryRepository) purgeTaskData(taskId uint) {
+	if r.taskIndex(taskId) < 0 {
+		delete(r.taskTags, taskId)
+		delete(r.notes, taskId)
+		r.remote = slices.DeleteFunc(r.remote, func(entry model.RemoteEntry) bool { return entry.TaskId == taskId })
+	}
+}
+
+// moveTaskData gives the tags, notes and remote entries of a task to another.
+func (r *MemoryRepository) moveTaskData(from, to uint) {
+	if tags, ok := r.taskTags[from]; ok {
+		r.taskTags[to] = tags
+		delete(r.taskTags, from)
+	}
+	if notes, ok := r.notes[from]; ok {
+		r.notes[to] = notes
+		delete(r.notes, from)
+	}
+	for i := range r.remote {
+		if r.remote[i].TaskId == from {
+			r.remote[i].TaskId = to
+		}
+	}
+}
+
+// GetTaskNotes returns the notes of the given tasks having any, by task id.
+func (r *MemoryRepository) GetTaskNotes(taskIds []uint) (map[uint]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := map[uint]string{}
+	for _, id := range taskIds {
+		if notes, ok := r.notes[id]; ok {
+			result[id] = notes
+		}
+	}
+	return result, nil
+}
+
+// SaveTaskNotes replaces the notes of a task, removing them when empty.
+func (r *MemoryRepository) SaveTaskNotes(taskId uint, notes string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if strings.TrimSpace(notes) == "" {
+		delete(r.notes, taskId)
+		return nil
+	}
+
+	if r.taskIndex(taskId) < 0 {
+		return gorm.ErrRecordNotFound
+	}
+	r.notes[taskId] = notes
+	return nil
+}
+
+// trash moves the task at the index to the trash. Its tags, notes and remote
+// entries are kept until it is purged, so it is restored with them.
+func (r *MemoryRepository) trash(i int) error {
+	task := r.tasks[i]
+	content, err := snapshot(&task)
+	if err != nil {
+		return err
+	}
+
+	r.tasks = slices.Delete(r.tasks, i, i+1)
+
+	r.lastTrashId++
+	r.trashed = append(r.trashed, model.TrashEntry{
+		ID:        r.lastTrashId,
+		TaskId:    task.ID,
+		Task:      content,
+		DeletedAt: r.now(),
+	})
+	return nil
+}
+
+func (r *MemoryRepository) record(action string, taskId uint, before, after *model.Task) error {
+	entry, err := newHistoryEntry(action, taskId, before, after)
+	if err != nil {
+		return err
+	}
+
+	r.lastHistoryId++
+	entry.ID = r.lastHistoryId
+	entry.CreatedAt = r.now()
+	r.history = append(r.history, entry)
+	return nil
+}
+
+// now returns the current time as the SQL repositories read it back: the
+// local wall clock, in UTC, with microseconds.
+func (r *MemoryRepository) now() model.LocalTimestamp {
+	return stored(model.LocalTimestamp{Time: r.clock()})
+}
+
+// saveTask inserts or replaces a task, returning its id. Its timestamps are
+// kept as the SQL repositories would store them.
+func (r *MemoryRepository) saveTask(task model.Task) uint {
+	task = cloneTask(task)
+	task.Duration = 0
+	task.Start = stored(task.Start)
+	if task.End != nil {
+		end := stored(*task.End)
+		task.End = &end
+	}
+
+	if i := r.taskIndex(task.ID); task.ID != 0 && i >= 0 {
+		r.tasks[i] = task
+		return task.ID
+	}
+
+	if task.ID == 0 {
+		r.lastTaskId++
+		task.ID = r.lastTaskId
+	}
+	r.tasks = append(r.tasks, task)
+	return task.ID
+}
+
+func (r *MemoryRepository) taskIndex(id uint) int {
+	return slices.IndexFunc(r.tasks, func(task model.Task) bool { return task.ID == id })
+}
+
+// findTasks returns the tasks matching the filter, with their duration, in the
+// ORDER of the SQL repositories.
+func (r *MemoryRepository) findTasks(filter func(task model.Task) bool) []model.Task {
+	var tasks []model.Task
+	for _, task := range r.tasks {
+		if filter(task) {
+			task = cloneTask(task)
+			task.Duration = r.duration(task)
+			tasks = append(tasks, task)
+		}
+	}
+
+	slices.SortStableFunc(tasks, func(a, b model.Task) int {
+		return cmp.Or(b.Start.Compare(a.Start.Time), cmp.Compare(a.ID, b.ID))
+	})
+	return tasks
+}
+
+func (r *MemoryRepository) sumDuration(filter func(task model.Task) bool) int {
+	total := 0
+	for _, task := range r.tasks {
+		if filter(task) {
+			total += r.duration(task)
+		}
+	}
+	return total
+}
+
+// duration counts whole seconds, as the DURATION expressions do.
+func (r *MemoryRepository) duration(task model.Task) int {
+	end := r.now()
+	if task.End != nil {
+		end = *task.End
+	}
+	return int(end.Unix() - task.Start.Unix())
+}
+
+func between(from, to time.Time) func(task model.Task) bool {
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	return func(task model.Task) bool {
+		day := taskDay(task)
+		return day >= first && day <= last
+	}
+}
+
+func taskDay(task model.Task) string {
+	return task.Start.Format(time.DateOnly)
+}
+
+func stored(timestamp model.LocalTimestamp) model.LocalTimestamp {
+	t := timestamp.Time
+	return model.LocalTimestamp{Time: time.Date(
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000*1000, time.UTC,
+	)}
+}
+
+func cloneTask(task model.Task) model.Task {
+	if task.End != nil {
+		end := *task.End
+		task.End = &end
+	}
+	if task.ExternalId != nil {
+		externalId := *task.ExternalId
+		task.ExternalId = &externalId
+	}
+	if task.Project != nil {
+		project := *task.Project
+		task.Project = &project
+	}
+	return task
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/util"
+	"gorm.io/gorm"
+)
+
+// RUST_MIGRATIONS_TABLES are the tables where the Rust app may keep track of
+// its own migrations, depending on the tool used.
+var RUST_MIGRATIONS_TABLES = []string{"__diesel_schema_migrations", "_sqlx_migrations"}
+
+type migration struct {
+	version int
+	name    string
+	up      func(tx *gorm.DB, rust rustSchema) error
+}
+
+// migrations are applied in order, and never changed once released: a schema
+// change is a new migration at the end.
+var migrations = []migration{
+	{1, "tasks and settings", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureSharedTables(tx, rust, &model.Task{}, &model.Settings{})
+	}},
+	{2, "integration cache", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.CacheEntry{})
+	}},
+	{3, "remote entries", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.RemoteEntry{})
+	}},
+	{4, "outbox", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.OutboxEntry{})
+	}},
+	{5, "task history", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.TaskHistory{})
+	}},
+	{6, "trash", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.TrashEntry{})
+	}},
+	{7, "projects", func(tx *gorm.DB, rust rustSchema) error {
+		if err := ensureTables(tx, &model.Project{}); err != nil {
+			return err
+		}
+
+		var names []string
+		if err := tx.Model(&model.Task{}).Distinct("project").Where("project IS NOT NULL AND project != ''").Order("project").Pluck("project", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := tx.Create(&model.Project{Name: name}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{8, "tags", func(tx *gorm.DB, rust rustSchema) error {
+		if err := ensureTables(tx, &model.Tag{}, &model.TaskTag{}); err != nil {
+			return err
+		}
+
+		var tasks []model.Task
+		if err := tx.Where(`"desc" LIKE ?`, "%#%").Find(&tasks).Error; err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := setTaskTags(tx, task.ID, util.DescriptionTags(task.Desc)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{9, "task notes", func(tx *gorm.DB, rust rustSchema) error {
+		return ensureTables(tx, &model.TaskNote{})
+	}},
+	{10, "search index", func(tx *gorm.DB, rust rustSchema) error {
+		return createSearchIndex(tx, false)
+	}},
+	{11, "default settings", func(tx *gorm.DB, rust rustSchema) error {
+		var count int64
+		if err := tx.Model(&model.Settings{}).Count(&count).Error; err != nil || count > 0 {
+			return err
+		}
+		settings := model.DefaultSettings()
+		return tx.Create(&settings).Error
+	}},
+}
+
+// rustSchema is the migration state of the Rust app in the database. Table is
+// empty when the Rust app has never managed it.
+type rustSchema struct {
+	Table   string
+	Version string
+}
+
+func (s rustSchema) managed() bool {
+	return s.Table != ""
+}
+
+// migrate brings the schema up to the last migration. Each migration runs
+// in a transaction along with its schema_version row. Databases created
+// before the versioning are adopted, as their tables are only created when
+// missing.
+func migrate(db *gorm.DB, now Clock) error {
+	if err := ensureTables(db, &model.SchemaVersion{}); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.Model(&model.SchemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&current).Error; err != nil {
+		return err
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than the supported %d, update mytime", current, latest)
+	}
+
+	rust, err := detectRustSchema(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx, rust); err != nil {
+				return err
+			}
+			return tx.Create(&model.SchemaVersion{
+				Version:   m.version,
+				Name:      m.name,
+				AppliedAt: model.LocalTimestamp{Time: now()},
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+func detectRustSchema(db *gorm.DB) (rustSchema, error) {
+	for _, table := range RUST_MIGRATIONS_TABLES {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+
+		var version string
+		if err := db.Table(table).Select("COALESCE(MAX(CAST(version AS TEXT)), '')").Scan(&version).Error; err != nil {
+			return rustSchema{}, err
+		}
+		return rustSchema{Table: table, Version: version}, nil
+	}
+	return rustSchema{}, nil
+}
+
+// ensureTables creates the tables owned by this app that are missing. The
+// existing ones are left as they are.
+func ensureTables(db *gorm.DB, models ...any) error {
+	for _, model := range models {
+		if db.Migrator().HasTable(model) {
+			continue
+		}
+		if err := db.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureSharedTables creates the tables shared with the Rust app when it has
+// never managed the database. The existing ones are never altered, as the
+// Rust app relies on them, so they must already have every column used here.
+func ensureSharedTables(db *gorm.DB, rust rustSchema, models ...any) error {
+	for _, model := range models {
+		if db.Migrator().HasTable(model) || rust.managed() {
+			if err := checkColumns(db, rust, model); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := db.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkColumns(db *gorm.DB, rust rustSchema, value any) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+
+	if !db.Migrator().HasTable(value) {
+		return fmt.Errorf("table %s is missing, %s", stmt.Table, rust.hint())
+	}
+
+	var missing []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+		if !db.Migrator().HasColumn(value, field.DBName) {
+			missing = append(missing, field.DBName)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("table %s has no %s columns, %s", stmt.Table, strings.Join(missing, ", "), rust.hint())
+	}
+	return nil
+}
+
+func (s rustSchema) hint() string {
+	if !s.managed() {
+		return "the database was not created by mytime"
+	}
+	return fmt.Sprintf("the Rust app schema (%s %s) is older than expected, update it and open the database with it first", s.Table, s.Version)
+}
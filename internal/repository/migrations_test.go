@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "mytime.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func schemaVersions(t *testing.T, db *gorm.DB) []int {
+	t.Helper()
+	var versions []int
+	must(t, db.Model(&model.SchemaVersion{}).Order("version").Pluck("version", &versions).Error)
+	return versions
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	must(t, migrate(db, time.Now))
+	must(t, migrate(db, time.Now))
+
+	if versions := schemaVersions(t, db); len(versions) != len(migrations) {
+		t.Fatalf("expected %d versions, got %v", len(migrations), versions)
+	}
+	for _, table := range []string{"tasks", "settings", "integration_cache", "remote_entries", "outbox", "task_history", "trash", "projects", "tags", "task_tags", "task_notes"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("expected table %s to be created", table)
+		}
+	}
+}
+
+func TestMigrateAdoptsUnversionedDatabase(t *testing.T) {
+	db := openTestDB(t)
+	must(t, db.AutoMigrate(&model.Task{}, &model.Settings{}, &model.CacheEntry{}, &model.RemoteEntry{}))
+	project := "mytime"
+	must(t, db.Create(&model.Task{Desc: "kept #Meeting", Project: &project, Start: model.LocalTimestamp{Time: time.Now()}}).Error)
+
+	must(t, migrate(db, time.Now))
+
+	var count int64
+	must(t, db.Model(&model.Task{}).Count(&count).Error)
+	if count != 1 {
+		t.Errorf("expected the task to be kept, got %d tasks", count)
+	}
+	if !db.Migrator().HasTable(&model.OutboxEntry{}) {
+		t.Error("expected the missing outbox table to be created")
+	}
+
+	var projects []string
+	must(t, db.Model(&model.Project{}).Pluck("name", &projects).Error)
+	if len(projects) != 1 || projects[0] != project {
+		t.Errorf("expected the project of the task to be created, got %v", projects)
+	}
+
+	var tags []string
+	must(t, db.Model(&model.Tag{}).Joins("JOIN task_tags ON task_tags.tag_id = tags.id").Pluck("name", &tags).Error)
+	if len(tags) != 1 || tags[0] != "meeting" {
+		t.Errorf("expected the tag of the description to be added, got %v", tags)
+	}
+}
+
+func TestMigrateKeepsSettings(t *testing.T) {
+	db := openTestDB(t)
+	must(t, db.AutoMigrate(&model.Settings{}))
+	must(t, db.Create(&model.Settings{ID: 3, WorkHours: "7,7,7,7,7,0,0", Theme: "dark", ViewType: "grouped"}).Error)
+
+	must(t, migrate(db, time.Now))
+
+	var settings []model.Settings
+	must(t, db.Find(&settings).Error)
+	if len(settings) != 1 || settings[0].ID != 3 || settings[0].WorkHours != "7,7,7,7,7,0,0" {
+		t.Errorf("expected only the existing settings, got %+v", settings)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	must(t, migrate(db, time.Now))
+	must(t, db.Create(&model.SchemaVersion{Version: 99, Name: "future"}).Error)
+
+	err := migrate(db, time.Now)
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a newer schema error, got %v", err)
+	}
+}
+
+func TestNewReturnsMigrationErrors(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "mytime.sqlite")
+	repo, err := New(dsn)
+	must(t, err)
+	must(t, repo.(*GormRepository).db.Create(&model.SchemaVersion{Version: 99, Name: "future"}).Error)
+	must(t, repo.Close())
+
+	repo, err = New(dsn)
+	if err == nil || repo != nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a newer schema error and no repository, got %v %v", repo, err)
+	}
+}
+
+func TestMigrateKeepsRustTables(t *testing.T) {
+	db := openTestDB(t)
+	must(t, db.Exec("CREATE TABLE __diesel_schema_migrations (version VARCHAR(50) PRIMARY KEY NOT NULL, run_on TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)").Error)
+	must(t, db.Exec("INSERT INTO __diesel_schema_migrations (version) VALUES ('20240101000000')").Error)
+	must(t, db.AutoMigrate(&model.Settings{}))
+	// an older Rust schema, without the favourite column
+	must(t, db.Exec(`CREATE TABLE tasks (id INTEGER PRIMARY KEY, "desc" VARCHAR NOT NULL, start TIMESTAMP NOT NULL, "end" TIMESTAMP, reported BOOLEAN DEFAULT false, external_id VARCHAR, project VARCHAR)`).Error)
+
+	err := migrate(db, time.Now)
+	if err == nil || !strings.Contains(err.Error(), "favourite") || !strings.Contains(err.Error(), "20240101000000") {
+		t.Fatalf("expected a missing favourite column error, got %v", err)
+	}
+	if db.Migrator().HasColumn(&model.Task{}, "favourite") {
+		t.Error("the Rust tasks table should not be altered")
+	}
+	if versions := schemaVersions(t, db); len(versions) != 0 {
+		t.Errorf("expected no version applied, got %v", versions)
+	}
+
+	must(t, db.Exec("ALTER TABLE tasks ADD COLUMN favourite BOOLEAN DEFAULT false").Error)
+	must(t, migrate(db, time.Now))
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/driver/postgres"
+)
+
+const POSTGRES_DURATION = "CAST(EXTRACT(EPOCH FROM COALESCE(\"end\", CAST(? AS TIMESTAMP)) - start) AS INTEGER)"
+
+var postgresDialect = dialect{
+	duration: POSTGRES_DURATION,
+	day: func(column string) string {
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD')", column)
+	},
+	concat: func(column, separator string) string {
+		return fmt.Sprintf("STRING_AGG(CAST(%s AS TEXT), '%s' ORDER BY %s)", column, separator, column)
+	},
+}
+
+func NewPostgresRepository(dsn string) (*GormRepository, error) {
+	return newGormRepository(postgres.Open(dsn), postgresDialect)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"strings"
 	"time"
 
 	"github.com/francescarpi/mytime/internal/model"
@@ -9,15 +10,66 @@ import (
 
 type Repository interface {
 	GetTasksByDate(date time.Time) ([]model.Task, error)
+	GetTasksBetween(from, to time.Time) ([]model.Task, error)
+	SearchTasks(query string, dateRange types.DateRange, limit int) ([]model.Task, error)
+	RebuildSearchIndex() error
 	GetTasksToSync() ([]types.TasksToSync, error)
 	GetWorkedDurationForDate(date time.Time, status types.TaskStatus) (int, error)
 	GetWeeklyWorkedDurationForDate(date time.Time) (int, error)
 	GetSettings() (*model.Settings, error)
-	CreateTask(description string, project, externalId *string) error
+	CreateTask(description string, project, externalId *string, tags []string) error
 	CloseOpenedTasks() error
 	CloseTask(id uint) error
 	GetTask(id uint) (*model.Task, error)
 	UpdateTask(task *model.Task) error
 	DeleteTask(id uint) error
 	SetTaskAsReported(id uint) error
+	GetCacheEntry(key string) (*model.CacheEntry, error)
+	SaveCacheEntry(key, value string) error
+	SaveRemoteEntry(taskIds []uint, entry model.RemoteEntry) error
+	GetRemoteEntry(taskId uint) (*model.RemoteEntry, error)
+	GetRemoteEntryTasks(entry *model.RemoteEntry) ([]model.Task, error)
+	MoveRemoteEntry(entry *model.RemoteEntry, instance, issueId string) error
+	DeleteRemoteEntry(entry *model.RemoteEntry) error
+	GetOutboxEntries() ([]model.OutboxEntry, error)
+	SaveOutboxEntry(entry *model.OutboxEntry) error
+	DeleteOutboxEntry(id uint) error
+	RestoreTask(id uint, snapshot *model.Task, action string) error
+	GetTaskHistory(taskId uint) ([]model.TaskHistory, error)
+	GetHistorySince(id uint) ([]model.TaskHistory, error)
+	GetLastHistoryId() (uint, error)
+	GetTrash() ([]model.TrashEntry, error)
+	RestoreFromTrash(id uint) error
+	PurgeTrashEntry(id uint) error
+	PurgeTrash(before time.Time) (int, error)
+	GetProjects() ([]model.Project, error)
+	GetProject(name string) (*model.Project, error)
+	SaveProject(project *model.Project) error
+	GetTags() ([]model.Tag, error)
+	GetTaskTags(taskIds []uint) (map[uint][]string, error)
+	SetTaskTags(taskId uint, tags []string) error
+	GetTaskNotes(taskIds []uint) (map[uint]string, error)
+	SaveTaskNotes(taskId uint, notes string) error
+	Close() error
+}
+
+// New returns the repository for the database of the DSN: PostgreSQL for the
+// postgres:// and postgresql:// schemes, SQLite otherwise. It fails when the
+// database can not be opened or migrated.
+func New(dsn string) (Repository, error) {
+	var repo *GormRepository
+	var err error
+	if isPostgres(dsn) {
+		repo, err = NewPostgresRepository(dsn)
+	} else {
+		repo, err = NewSqliteRepository(dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func isPostgres(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
 }
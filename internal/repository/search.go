@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// LEGACY_SEARCH_TRIGGERS were put on the tasks table by the first versions of
+// the search. They are dropped, as every write to the tasks, the Rust app's
+// too, fails with them when the SQLite library lacks FTS5.
+var LEGACY_SEARCH_TRIGGERS = []string{"tasks_fts_insert", "tasks_fts_delete", "tasks_fts_update"}
+
+var errNoFts5 = errors.New("the SQLite library has no FTS5, build with -tags sqlite_fts5")
+
+// fts5Enabled tells whether the SQLite library has FTS5.
+func fts5Enabled(db *gorm.DB) (bool, error) {
+	var enabled bool
+	err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error
+	return enabled, err
+}
+
+// createSearchIndex drops the legacy triggers and creates the tasks_fts index
+// of the tasks, filled with them. Without FTS5 the index is left out, unless
+// required, and the search falls back to LIKE.
+func createSearchIndex(db *gorm.DB, required bool) error {
+	if db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+
+	for _, name := range LEGACY_SEARCH_TRIGGERS {
+		if err := db.Exec("DROP TRIGGER IF EXISTS " + name).Error; err != nil {
+			return err
+		}
+	}
+
+	enabled, err := fts5Enabled(db)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if required {
+			return errNoFts5
+		}
+		return nil
+	}
+
+	if err := db.Exec("DROP TABLE IF EXISTS tasks_fts").Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`CREATE VIRTUAL TABLE tasks_fts USING fts5("desc", project, external_id)`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`INSERT INTO tasks_fts (rowid, "desc", project, external_id) SELECT id, "desc", project, external_id FROM tasks`).Error
+}
+
+// searchIndexReady tells whether the tasks_fts index can be searched: FTS5 is
+// there and the index was created by the migration or a rebuild. The index
+// of the legacy versions, reading its content from the tasks, is not used.
+func searchIndexReady(db *gorm.DB) (bool, error) {
+	enabled, err := fts5Enabled(db)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	var count int64
+	err = db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'tasks_fts' AND sql NOT LIKE '%content=%'").Scan(&count).Error
+	return count > 0, err
+}
+
+// refreshSearchIndex brings the tasks_fts index up to date with the tasks
+// before a search, as the tasks written by the Rust app are not indexed when
+// written: the rows of the tasks gone or changed are removed, and the missing
+// ones added.
+func refreshSearchIndex(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM tasks_fts WHERE rowid IN (
+			SELECT tasks_fts.rowid FROM tasks_fts LEFT JOIN tasks ON tasks.id = tasks_fts.rowid
+			WHERE tasks.id IS NULL OR tasks."desc" IS NOT tasks_fts."desc"
+				OR tasks.project IS NOT tasks_fts.project OR tasks.external_id IS NOT tasks_fts.external_id
+		)`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO tasks_fts (rowid, "desc", project, external_id)
+			SELECT id, "desc", project, external_id FROM tasks WHERE id NOT IN (SELECT rowid FROM tasks_fts)`).Error
+	})
+}
+
+// searchWords splits a search query in the words every task found must match.
+func searchWords(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// ftsQuery turns the words into an FTS5 query matching the tasks having all
+// of them, as prefixes, so no FTS5 syntax typed is interpreted.
+func ftsQuery(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// likePattern matches the word anywhere in a column, with the LIKE wildcards
+// escaped.
+func likePattern(word string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word) + "%"
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestSearchIndexLeavesTasksAlone(t *testing.T) {
+	repo := mustOpen(t)(NewSqliteRepository(filepath.Join(t.TempDir(), "mytime.sqlite")))
+
+	// the Rust app writes to the tasks, with or without FTS5 in its library
+	var triggers int64
+	must(t, repo.db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND tbl_name = 'tasks'").Scan(&triggers).Error)
+	if triggers != 0 {
+		t.Errorf("expected no triggers on tasks, got %d", triggers)
+	}
+
+	enabled, err := fts5Enabled(repo.db)
+	must(t, err)
+	if enabled != repo.fts || enabled != repo.db.Migrator().HasTable("tasks_fts") {
+		t.Errorf("expected the index only with FTS5 (%v), got fts %v", enabled, repo.fts)
+	}
+	if err := repo.RebuildSearchIndex(); !enabled && !errors.Is(err, errNoFts5) {
+		t.Errorf("expected rebuilding without FTS5 to fail, got %v", err)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "mytime.sqlite")
+	repo := mustOpen(t)(NewSqliteRepository(dsn))
+	if !repo.fts {
+		t.Skip("SQLite built without FTS5, run with -tags sqlite_fts5")
+	}
+
+	// the tasks written by the Rust app are indexed on the next search
+	must(t, repo.db.Exec(`INSERT INTO tasks ("desc", start, reported, favourite) VALUES ('written by rust', '2025-02-26 09:00:00', false, false)`).Error)
+	expectFound(t, repo, "rust", 1)
+
+	must(t, repo.db.Exec(`UPDATE tasks SET "desc" = 'renamed meanwhile'`).Error)
+	expectFound(t, repo, "rust", 0)
+	expectFound(t, repo, "meanwhile", 1)
+
+	must(t, repo.db.Exec(`DELETE FROM tasks`).Error)
+	expectFound(t, repo, "meanwhile", 0)
+
+	// an index missing, as the database was migrated without FTS5, is created
+	// on demand
+	must(t, repo.CreateTask("review mytime", nil, nil, nil))
+	must(t, repo.db.Exec("DROP TABLE tasks_fts").Error)
+	repo = mustOpen(t)(NewSqliteRepository(dsn))
+	if repo.fts {
+		t.Fatal("expected the search without index")
+	}
+	// LIKE matches anywhere, the index only prefixes
+	expectFound(t, repo, "view", 1)
+
+	must(t, repo.RebuildSearchIndex())
+	expectFound(t, repo, "view", 0)
+	expectFound(t, repo, "rev", 1)
+	repo = mustOpen(t)(NewSqliteRepository(dsn))
+	if !repo.fts {
+		t.Error("expected the rebuilt index to be used")
+	}
+}
+
+func TestSearchIndexReplacesLegacyTriggers(t *testing.T) {
+	db := openTestDB(t)
+	must(t, migrate(db, time.Now))
+	must(t, db.Where("version >= ?", 10).Delete(&model.SchemaVersion{}).Error)
+	for _, name := range LEGACY_SEARCH_TRIGGERS {
+		must(t, db.Exec("CREATE TRIGGER "+name+" AFTER INSERT ON tasks BEGIN SELECT 1; END").Error)
+	}
+
+	must(t, migrate(db, time.Now))
+
+	var triggers int64
+	must(t, db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger'").Scan(&triggers).Error)
+	if triggers != 0 {
+		t.Errorf("expected the legacy triggers to be dropped, got %d", triggers)
+	}
+}
+
+func expectFound(t *testing.T, repo *GormRepository, query string, expected int) {
+	t.Helper()
+	tasks, err := repo.SearchTasks(query, types.DateRange{}, 0)
+	must(t, err)
+	if len(tasks) != expected {
+		t.Errorf("%s: expected %d tasks, got %+v", query, expected, tasks)
+	}
+}
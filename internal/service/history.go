@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/repository"
+)
+
+// HistoryChange is a field of a task changed by a history entry.
+type HistoryChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+func (s *Service) GetTaskHistory(taskId uint) ([]model.TaskHistory, error) {
+	return s.Repo.GetTaskHistory(taskId)
+}
+
+func (s *Service) CanUndo() bool {
+	return len(s.undo) > 0
+}
+
+func (s *Service) CanRedo() bool {
+	return len(s.redo) > 0
+}
+
+// Undo brings the tasks changed by the last change made through the service
+// back to how they were before it.
+func (s *Service) Undo() error {
+	if !s.CanUndo() {
+		return errors.New("nothing to undo")
+	}
+
+	step := s.undo[len(s.undo)-1]
+	for i := len(step) - 1; i >= 0; i-- {
+		if err := s.restore(step[i].TaskId, step[i].Before, model.HISTORY_UNDO); err != nil {
+			return err
+		}
+	}
+
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, step)
+	return nil
+}
+
+// Redo applies again the last change undone.
+func (s *Service) Redo() error {
+	if !s.CanRedo() {
+		return errors.New("nothing to redo")
+	}
+
+	step := s.redo[len(s.redo)-1]
+	for _, entry := range step {
+		if err := s.restore(entry.TaskId, entry.After, model.HISTORY_REDO); err != nil {
+			return err
+		}
+	}
+
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, step)
+	return nil
+}
+
+// track runs a change and pushes the history entries it added to the undo
+// stack, as a single step.
+func (s *Service) track(change func() error) error {
+	last, err := s.Repo.GetLastHistoryId()
+	if err != nil {
+		return err
+	}
+
+	changeErr := change()
+
+	entries, err := s.Repo.GetHistorySince(last)
+	if err != nil {
+		return errors.Join(changeErr, err)
+	}
+	if len(entries) > 0 {
+		s.undo = append(s.undo, entries)
+		s.redo = nil
+	}
+	return changeErr
+}
+
+func (s *Service) restore(taskId uint, snapshot, action string) error {
+	task, err := repository.ParseTaskSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.Repo.RestoreTask(taskId, task, action)
+}
+
+// HistoryChanges lists the fields changed by a history entry. All the fields
+// set are listed for a created or a deleted task.
+func HistoryChanges(entry model.TaskHistory) ([]HistoryChange, error) {
+	before, err := repository.ParseTaskSnapshot(entry.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := repository.ParseTaskSnapshot(entry.After)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeFields, afterFields := snapshotFields(before), snapshotFields(after)
+
+	var changes []HistoryChange
+	for i, field := range []string{"project", "desc", "external_id", "start", "end", "reported", "favourite"} {
+		if beforeFields[i] != afterFields[i] {
+			changes = append(changes, HistoryChange{Field: field, Before: beforeFields[i], After: afterFields[i]})
+		}
+	}
+	return changes, nil
+}
+
+func snapshotFields(task *model.Task) []string {
+	if task == nil {
+		return make([]string, 7)
+	}
+
+	end := ""
+	if task.End != nil {
+		end = task.End.Format("2006-01-02 15:04:05")
+	}
+
+	externalId := ""
+	if task.ExternalId != nil {
+		externalId = *task.ExternalId
+	}
+
+	return []string{
+		task.ProjectName(),
+		task.Desc,
+		externalId,
+		task.Start.Format("2006-01-02 15:04:05"),
+		end,
+		strconv.FormatBool(task.Reported),
+		strconv.FormatBool(task.Favourite),
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+)
+
+func TestUndoRedo(t *testing.T) {
+	s, clock := newTestService(t)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	must(s.CreateTask("first", nil, nil, nil))
+	clock.Advance(time.Hour)
+	must(s.CreateTask("second", nil, nil, nil))
+
+	task, err := s.Repo.GetTask(2)
+	must(err)
+	task.Desc = "renamed"
+	must(s.UpdateTask(task))
+	must(s.DeleteTask(1))
+
+	// the deleted task comes back with its id
+	must(s.Undo())
+	first, err := s.Repo.GetTask(1)
+	must(err)
+	if first.Desc != "first" || first.End == nil {
+		t.Errorf("expected the closed first task back, got %+v", first)
+	}
+
+	must(s.Undo())
+	second, err := s.Repo.GetTask(2)
+	must(err)
+	if second.Desc != "second" {
+		t.Errorf("expected the description to be undone, got %q", second.Desc)
+	}
+
+	// undoing the creation of the second task reopens the first one
+	must(s.Undo())
+	if _, err := s.Repo.GetTask(2); err == nil {
+		t.Error("expected the second task to be removed")
+	}
+	first, err = s.Repo.GetTask(1)
+	must(err)
+	if !first.IsOpen() {
+		t.Error("expected the first task to be open again")
+	}
+
+	must(s.Redo())
+	second, err = s.Repo.GetTask(2)
+	must(err)
+	if second.Desc != "second" || !second.IsOpen() {
+		t.Errorf("expected the second task to be created again, got %+v", second)
+	}
+
+	must(s.SetTaskAsReported(2))
+	if s.CanRedo() {
+		t.Error("a new change should clear the redo steps")
+	}
+
+	history, err := s.GetTaskHistory(2)
+	must(err)
+	var actions []string
+	for _, entry := range history {
+		actions = append(actions, entry.Action)
+	}
+	expected := []string{
+		model.HISTORY_CREATE, model.HISTORY_UPDATE, model.HISTORY_UNDO, model.HISTORY_UNDO, model.HISTORY_REDO, model.HISTORY_REPORT,
+	}
+	if !slices.Equal(actions, expected) {
+		t.Fatalf("expected %v, got %v", expected, actions)
+	}
+
+	changes, err := HistoryChanges(history[1])
+	must(err)
+	if len(changes) != 1 || changes[0] != (HistoryChange{Field: "desc", Before: "second", After: "renamed"}) {
+		t.Errorf("expected the description change, got %+v", changes)
+	}
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/service/plugin"
+	"github.com/francescarpi/mytime/internal/service/redmine"
+	"github.com/francescarpi/mytime/internal/service/webhook"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const (
+	REDMINE = "redmine"
+	WEBHOOK = "webhook"
+	PLUGIN  = "plugin"
+)
+
+// Integration is the contract every time tracking backend has to fulfil to be
+// used from the sync view. SendTask returns the id of the remote entry created,
+// or an empty string when the backend does not provide one.
+type Integration interface {
+	LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error)
+	SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error)
+}
+
+var ErrNotSupported = errors.New("not supported by the integration")
+
+// Validator is implemented by the integrations able to check that an external
+// id exists before the task is synced.
+type Validator interface {
+	ValidateId(ctx context.Context, externalId, project string) (*types.Issue, error)
+}
+
+// IssueBrowser is implemented by the integrations able to list the issues
+// assigned to the current user.
+type IssueBrowser interface {
+	ListMyIssues(ctx context.Context, filter types.IssueFilter) ([]types.Issue, int, error)
+}
+
+// IssueLocator is implemented by the integrations sending to more than one
+// server, to tell the instance an external id goes to and its issue id there.
+type IssueLocator interface {
+	LocateIssue(externalId, project string) (instance, issueId string, err error)
+}
+
+// TimeEntryLister is implemented by the integrations able to list the time
+// entries already recorded for the current user.
+type TimeEntryLister interface {
+	ListMyTimeEntries(ctx context.Context, from, to time.Time) ([]types.TimeEntry, error)
+}
+
+// EntryEditor is implemented by the integrations able to amend or delete a
+// time entry after it has been synced. The task is the group linked to the
+// entry, whose external id may no longer be the one it was synced to.
+type EntryEditor interface {
+	UpdateEntry(ctx context.Context, entry model.RemoteEntry, task types.TasksToSync) error
+	DeleteEntry(ctx context.Context, entry model.RemoteEntry) error
+}
+
+// CustomFieldsLoader is implemented by the integrations whose time entries
+// take custom fields.
+type CustomFieldsLoader interface {
+	LoadCustomFields(ctx context.Context, task types.TasksToSync) ([]types.CustomField, error)
+}
+
+// IssueUpdater is implemented by the integrations able to change the status
+// and progress of an issue, or to comment on it.
+type IssueUpdater interface {
+	LoadIssueStatuses(ctx context.Context, task types.TasksToSync) ([]types.IssueStatus, *types.IssueStatus, int, error)
+	UpdateIssue(ctx context.Context, task types.TasksToSync, update types.IssueUpdate) error
+}
+
+// Previewer is implemented by the integrations able to show the request they
+// would send for a sync group, without sending it.
+type Previewer interface {
+	PreviewTask(task types.TasksToSync, activityId int) (*types.RequestPreview, error)
+}
+
+// Cacheable is implemented by the integrations that keep their own data in
+// the local cache. Refresh makes the next loads skip the cached data.
+type Cacheable interface {
+	SetCache(cache types.Cache)
+	Refresh()
+}
+
+// New builds the integrations configured in the settings. Redmine is used as
+// the default one when no integration has been set, to stay compatible with the
+// Rust version.
+func New(service *service.Service) (*Router, error) {
+	settings, err := service.Repo.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	kind := REDMINE
+	if settings.Integration != nil && *settings.Integration != "" {
+		kind = *settings.Integration
+	}
+
+	router, err := NewRouter(kind, []byte(settings.IntegrationConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	router.SetCache(service)
+	router.SetProjects(service)
+	service.Rounder = router
+	return router, nil
+}
+
+// Build creates an integration of the given kind from its raw JSON config.
+func Build(kind string, config []byte) (Integration, error) {
+	switch kind {
+	case REDMINE:
+		return redmine.NewRedmine(config)
+	case WEBHOOK:
+		return webhook.NewWebhook(config)
+	case PLUGIN:
+		return plugin.NewPlugin(config)
+	}
+	return nil, fmt.Errorf("unknown integration: %s", kind)
+}
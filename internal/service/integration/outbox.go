@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// OutboxResult is the outcome of sending an outbox entry again. Task is the
+// group as sent, rounded. Discarded tells the entry was removed, as its tasks
+// changed, with Err telling why.
+type OutboxResult struct {
+	Entry     model.OutboxEntry
+	Task      types.TasksToSync
+	Err       error
+	Discarded bool
+}
+
+// CountOutboxResults returns how many entries were sent, failed and were
+// discarded.
+func CountOutboxResults(results []OutboxResult) (sent, failed, discarded int) {
+	for _, result := range results {
+		switch {
+		case result.Discarded:
+			discarded++
+		case result.Err != nil:
+			failed++
+		default:
+			sent++
+		}
+	}
+	return sent, failed, discarded
+}
+
+// Sync sends a group of tasks and marks them as synced. When the integration
+// fails, the group is kept in the outbox to be sent later.
+func (r *Router) Sync(ctx context.Context, s *service.Service, task types.TasksToSync, activityId int) error {
+	name, integration := r.Resolve(task)
+	_, err := r.send(ctx, s, name, integration, task, activityId)
+	return err
+}
+
+// Defer moves a group of tasks to the outbox without sending it.
+func (r *Router) Defer(s *service.Service, task types.TasksToSync, activityId int) error {
+	name, _ := r.Resolve(task)
+	return s.AddToOutbox(task, name, activityId, nil)
+}
+
+// SendOutbox sends the given outbox entries again, to the integration they
+// were meant for. The group of each entry is built from its tasks as they are
+// now, and the entries whose tasks are gone or regrouped are discarded.
+func (r *Router) SendOutbox(ctx context.Context, s *service.Service, entries []model.OutboxEntry) []OutboxResult {
+	var results []OutboxResult
+
+	for _, entry := range entries {
+		results = append(results, r.sendOutboxEntry(ctx, s, entry))
+	}
+
+	return results
+}
+
+func (r *Router) sendOutboxEntry(ctx context.Context, s *service.Service, entry model.OutboxEntry) OutboxResult {
+	result := OutboxResult{Entry: entry}
+
+	payload, err := s.OutboxPayload(entry)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Task, result.Err = s.OutboxTask(payload)
+	if errors.Is(result.Err, service.ErrOutboxStale) {
+		if err := s.DiscardOutboxEntry(entry); err != nil {
+			result.Err = err
+			return result
+		}
+		result.Discarded = true
+		return result
+	}
+	if result.Err != nil {
+		return result
+	}
+
+	name, integration := entry.Integration, r.integrations[entry.Integration]
+	if integration == nil {
+		name, integration = r.Resolve(result.Task)
+	}
+
+	result.Task, result.Err = r.send(ctx, s, name, integration, result.Task, payload.ActivityId)
+	return result
+}
+
+// send rounds the group and sends it, returning it as sent.
+func (r *Router) send(ctx context.Context, s *service.Service, name string, integration Integration, task types.TasksToSync, activityId int) (types.TasksToSync, error) {
+	task = r.Round(task)
+	remoteId, err := integration.SendTask(ctx, task, activityId)
+	if err != nil {
+		if outboxErr := s.AddToOutbox(task, name, activityId, err); outboxErr != nil {
+			log.Println("Error adding task to the outbox:", outboxErr)
+		}
+		return task, err
+	}
+
+	key := locateIssue(name, integration, task.ExternalId, task.Project)
+	return task, s.MarkAsSynced(task, model.RemoteEntry{
+		Integration: name,
+		Instance:    key.Instance,
+		IssueId:     key.IssueId,
+		RemoteId:    remoteId,
+	})
+}
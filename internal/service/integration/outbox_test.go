@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/repository"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// stubIntegration records the groups sent, failing with err when set.
+type stubIntegration struct {
+	sent []types.TasksToSync
+	err  error
+}
+
+func (s *stubIntegration) LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error) {
+	return nil, nil, nil
+}
+
+func (s *stubIntegration) SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.sent = append(s.sent, task)
+	return "42", nil
+}
+
+func newOutboxTest(t *testing.T) (*Router, *stubIntegration, *service.Service) {
+	t.Helper()
+	clock := repository.NewFakeClock(time.Date(2025, 2, 26, 9, 0, 0, 0, time.Local))
+	s := &service.Service{Repo: repository.NewMemoryRepository(clock.Now)}
+
+	issue := "ISSUE-1"
+	for range 2 {
+		if err := s.CreateTask("review", nil, &issue, nil); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Hour)
+	}
+	if err := s.Repo.CloseOpenedTasks(); err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &stubIntegration{}
+	router := &Router{defaultName: "stub", integrations: map[string]Integration{"stub": stub}}
+	return router, stub, s
+}
+
+func TestSendOutbox(t *testing.T) {
+	router, stub, s := newOutboxTest(t)
+
+	stub.err = errors.New("timeout")
+	task := s.GetTasksToSync()[0]
+	if err := router.Sync(context.Background(), s, task, 9); err == nil {
+		t.Fatal("expected the sync to fail")
+	}
+
+	// retried with the description edited meanwhile
+	edited, _ := s.Repo.GetTask(2)
+	edited.Desc = "code review"
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+	edited, _ = s.Repo.GetTask(1)
+	edited.Desc = "code review"
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox, _ := s.GetOutbox()
+	results := router.SendOutbox(context.Background(), s, outbox)
+	if len(results) != 1 || results[0].Err == nil || results[0].Discarded {
+		t.Fatalf("expected the retry to fail, got %+v", results)
+	}
+	outbox, _ = s.GetOutbox()
+	if len(outbox) != 1 || outbox[0].Attempts != 2 {
+		t.Fatalf("expected the entry kept with 2 attempts, got %+v", outbox)
+	}
+
+	stub.err = nil
+	results = router.SendOutbox(context.Background(), s, outbox)
+	if sent, failed, discarded := CountOutboxResults(results); sent != 1 || failed != 0 || discarded != 0 {
+		t.Fatalf("expected the entry sent, got %+v", results)
+	}
+	if len(stub.sent) != 1 || stub.sent[0].Desc != "code review" || stub.sent[0].Duration != 7200 {
+		t.Errorf("expected the edited group sent, got %+v", stub.sent)
+	}
+
+	if outbox, _ := s.GetOutbox(); len(outbox) != 0 {
+		t.Errorf("expected the outbox empty, got %+v", outbox)
+	}
+	if remote := s.GetRemoteEntry(1); remote == nil || remote.RemoteId != "42" {
+		t.Errorf("expected the tasks linked to the remote entry, got %+v", remote)
+	}
+	if tasks := s.GetTasksToSync(); len(tasks) != 0 {
+		t.Errorf("expected the tasks reported, got %+v", tasks)
+	}
+}
+
+func TestSendOutboxDiscardsDeletedTasks(t *testing.T) {
+	router, stub, s := newOutboxTest(t)
+
+	task := s.GetTasksToSync()[0]
+	if err := router.Defer(s, task, 9); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{1, 2} {
+		if err := s.DeleteTask(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outbox, _ := s.GetOutbox()
+	results := router.SendOutbox(context.Background(), s, outbox)
+	if len(results) != 1 || !results[0].Discarded || !errors.Is(results[0].Err, service.ErrOutboxStale) {
+		t.Fatalf("expected the entry discarded, got %+v", results)
+	}
+	if len(stub.sent) != 0 {
+		t.Errorf("expected nothing sent for deleted tasks, got %+v", stub.sent)
+	}
+	if outbox, _ := s.GetOutbox(); len(outbox) != 0 {
+		t.Errorf("expected the entry removed, got %+v", outbox)
+	}
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"encoding/json"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// PreviewItem is a sync group with the activity it would be sent with.
+type PreviewItem struct {
+	Task     types.TasksToSync
+	Activity types.Activity
+}
+
+// PreviewEntry is the request that would be sent for a sync group. The body is
+// kept as JSON when it is valid JSON, so the export is readable.
+type PreviewEntry struct {
+	Integration string          `json:"integration"`
+	ExternalId  string          `json:"external_id"`
+	Date        string          `json:"date"`
+	Desc        string          `json:"desc"`
+	Duration    int             `json:"duration"`
+	RawDuration int             `json:"raw_duration"`
+	Activity    string          `json:"activity"`
+	Method      string          `json:"method,omitempty"`
+	Url         string          `json:"url,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+type PreviewTotal struct {
+	Key      string  `json:"key"`
+	Duration int     `json:"duration"`
+	Hours    float64 `json:"hours"`
+}
+
+type SyncPreview struct {
+	Entries       []PreviewEntry `json:"entries"`
+	TotalsByDay   []PreviewTotal `json:"totals_by_day"`
+	TotalsByIssue []PreviewTotal `json:"totals_by_issue"`
+}
+
+// Preview builds the requests the sync would send, without sending anything.
+func (r *Router) Preview(items []PreviewItem) SyncPreview {
+	preview := SyncPreview{Entries: []PreviewEntry{}}
+	byDay := map[string]int{}
+	byIssue := map[string]int{}
+
+	for _, item := range items {
+		item.Task = r.Round(item.Task)
+		name, integration := r.Resolve(item.Task)
+
+		entry := PreviewEntry{
+			Integration: name,
+			ExternalId:  item.Task.ExternalId,
+			Date:        item.Task.Date,
+			Desc:        item.Task.Desc,
+			Duration:    item.Task.Duration,
+			RawDuration: item.Task.RawDuration,
+			Activity:    item.Activity.Name,
+		}
+
+		if previewer, ok := integration.(Previewer); ok {
+			request, err := previewer.PreviewTask(item.Task, item.Activity.Id)
+			if err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.Method = request.Method
+				entry.Url = request.Url
+				entry.Body = previewBody(request.Body)
+			}
+		} else {
+			entry.Error = ErrNotSupported.Error()
+		}
+
+		preview.Entries = append(preview.Entries, entry)
+		byDay[item.Task.Date] += item.Task.Duration
+		byIssue[item.Task.ExternalId] += item.Task.Duration
+	}
+
+	preview.TotalsByDay = previewTotals(byDay)
+	preview.TotalsByIssue = previewTotals(byIssue)
+
+	return preview
+}
+
+func previewBody(body string) json.RawMessage {
+	if json.Valid([]byte(body)) {
+		return json.RawMessage(body)
+	}
+
+	encoded, _ := json.Marshal(body)
+	return encoded
+}
+
+func previewTotals(durations map[string]int) []PreviewTotal {
+	totals := []PreviewTotal{}
+	for _, key := range slices.Sorted(maps.Keys(durations)) {
+		totals = append(totals, PreviewTotal{
+			Key:      key,
+			Duration: durations[key],
+			Hours:    math.Round(float64(durations[key])/36) / 100,
+		})
+	}
+	return totals
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestPreview(t *testing.T) {
+	config := `{
+		"url": "https://redmine.example.com",
+		"integrations": {
+			"tools": {"type": "webhook", "config": {"url": "https://tools.example.com/{{.ExternalId}}", "body": "hours={{.Hours}}"}}
+		},
+		"routes": [{"external_id": "^TOOLS-\\d+$", "integration": "tools"}]
+	}`
+
+	router, err := NewRouter(REDMINE, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preview := router.Preview([]PreviewItem{
+		{types.TasksToSync{ExternalId: "1234", Date: "2025-01-01", Duration: 5400, Desc: "Fix"}, types.Activity{Id: 9, Name: "Development"}},
+		{types.TasksToSync{ExternalId: "1234", Date: "2025-01-02", Duration: 1800, Desc: "Fix"}, types.Activity{Id: 9, Name: "Development"}},
+		{types.TasksToSync{ExternalId: "TOOLS-1", Date: "2025-01-02", Duration: 3600, Desc: "Deploy"}, types.Activity{Id: 1, Name: "Default"}},
+	})
+
+	redmineBody := `{"time_entry":{"issue_id":"1234","hours":"1h30m","comments":"Fix","spent_on":"2025-01-01","activity_id":9}}`
+	if entry := preview.Entries[0]; entry.Url != "https://redmine.example.com/time_entries.json" || string(entry.Body) != redmineBody {
+		t.Errorf("Unexpected Redmine preview %v %s", entry.Url, entry.Body)
+	}
+
+	if entry := preview.Entries[2]; entry.Integration != "tools" || entry.Url != "https://tools.example.com/TOOLS-1" || string(entry.Body) != `"hours=1"` {
+		t.Errorf("Unexpected webhook preview %v %v %s", entry.Integration, entry.Url, entry.Body)
+	}
+
+	expectedDays := []PreviewTotal{{"2025-01-01", 5400, 1.5}, {"2025-01-02", 5400, 1.5}}
+	for i, total := range expectedDays {
+		if preview.TotalsByDay[i] != total {
+			t.Errorf("Expected day total %v, got %v", total, preview.TotalsByDay[i])
+		}
+	}
+
+	expectedIssues := []PreviewTotal{{"1234", 7200, 2}, {"TOOLS-1", 3600, 1}}
+	for i, total := range expectedIssues {
+		if preview.TotalsByIssue[i] != total {
+			t.Errorf("Expected issue total %v, got %v", total, preview.TotalsByIssue[i])
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/util"
+)
+
+// RouterConfig holds the routing keys stored next to the default integration
+// config in Settings.IntegrationConfig. Unknown keys are ignored by the Rust
+// version, so the same JSON keeps working there.
+type RouterConfig struct {
+	Integrations    map[string]InstanceConfig `json:"integrations"`
+	Routes          []Route                   `json:"routes"`
+	Rounding        util.Rounding             `json:"rounding"`
+	ProjectRounding map[string]util.Rounding  `json:"project_rounding"`
+}
+
+type InstanceConfig struct {
+	Type     string          `json:"type"`
+	Config   json.RawMessage `json:"config"`
+	Rounding *util.Rounding  `json:"rounding"`
+}
+
+// Route sends a task to an integration instance when its project name or its
+// external id matches. The first matching route wins.
+type Route struct {
+	Project     string `json:"project"`
+	ExternalId  string `json:"external_id"`
+	Integration string `json:"integration"`
+
+	externalId *regexp.Regexp
+}
+
+func (r *Route) matches(task types.TasksToSync) bool {
+	if r.Project != "" && !strings.EqualFold(r.Project, task.Project) {
+		return false
+	}
+	if r.externalId != nil && !r.externalId.MatchString(task.ExternalId) {
+		return false
+	}
+	return r.Project != "" || r.externalId != nil
+}
+
+// ProjectFinder returns the project of the given name, or nil when there is
+// none.
+type ProjectFinder interface {
+	GetProject(name string) *model.Project
+}
+
+type Router struct {
+	defaultName         string
+	integrations        map[string]Integration
+	routes              []Route
+	projects            ProjectFinder
+	rounding            util.Rounding
+	integrationRounding map[string]util.Rounding
+	projectRounding     map[string]util.Rounding
+}
+
+func NewRouter(kind string, config []byte) (*Router, error) {
+	var routerConfig RouterConfig
+	if err := json.Unmarshal(config, &routerConfig); err != nil {
+		return nil, err
+	}
+
+	defaultIntegration, err := Build(kind, config)
+	if err != nil {
+		return nil, err
+	}
+
+	router := &Router{
+		defaultName:         kind,
+		integrations:        map[string]Integration{kind: defaultIntegration},
+		rounding:            routerConfig.Rounding,
+		integrationRounding: map[string]util.Rounding{},
+		projectRounding:     map[string]util.Rounding{},
+	}
+
+	if err := routerConfig.Rounding.Validate(); err != nil {
+		return nil, err
+	}
+
+	for name, instance := range routerConfig.Integrations {
+		integration, err := Build(instance.Type, instance.Config)
+		if err != nil {
+			return nil, fmt.Errorf("integration %s: %w", name, err)
+		}
+		router.integrations[name] = integration
+
+		if instance.Rounding != nil {
+			if err := instance.Rounding.Validate(); err != nil {
+				return nil, fmt.Errorf("integration %s: %w", name, err)
+			}
+			router.integrationRounding[name] = *instance.Rounding
+		}
+	}
+
+	for project, rounding := range routerConfig.ProjectRounding {
+		if err := rounding.Validate(); err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+		router.projectRounding[strings.ToLower(project)] = rounding
+	}
+
+	for _, route := range routerConfig.Routes {
+		if _, ok := router.integrations[route.Integration]; !ok {
+			return nil, fmt.Errorf("route to unknown integration: %s", route.Integration)
+		}
+		if route.ExternalId != "" {
+			route.externalId, err = regexp.Compile(route.ExternalId)
+			if err != nil {
+				return nil, fmt.Errorf("invalid external id route %s: %w", route.ExternalId, err)
+			}
+		}
+		router.routes = append(router.routes, route)
+	}
+
+	return router, nil
+}
+
+// Resolve returns the name and the integration a task has to be sent to. The
+// default integration of its project wins over the routes.
+func (r *Router) Resolve(task types.TasksToSync) (string, Integration) {
+	if project := r.project(task); project != nil {
+		if integration, ok := r.integrations[project.DefaultIntegration]; ok {
+			return project.DefaultIntegration, integration
+		}
+	}
+
+	for _, route := range r.routes {
+		if route.matches(task) {
+			return route.Integration, r.integrations[route.Integration]
+		}
+	}
+	return r.defaultName, r.integrations[r.defaultName]
+}
+
+// Rounding returns the rounding policy of a task group: the one of its project,
+// or else the one of its integration, or else the global one.
+func (r *Router) Rounding(task types.TasksToSync) util.Rounding {
+	if rounding, ok := r.projectRounding[strings.ToLower(task.Project)]; ok {
+		return rounding
+	}
+
+	name, _ := r.Resolve(task)
+	if rounding, ok := r.integrationRounding[name]; ok {
+		return rounding
+	}
+
+	return r.rounding
+}
+
+// Round sets the reported duration of a task group from its raw duration. It
+// can be applied more than once.
+func (r *Router) Round(task types.TasksToSync) types.TasksToSync {
+	if task.RawDuration == 0 {
+		task.RawDuration = task.Duration
+	}
+	task.Duration = r.Rounding(task).Apply(task.RawDuration)
+	return task
+}
+
+// LoadActivities returns the activities of the integration a task is routed
+// to. The default activity of its project is preselected when listed.
+func (r *Router) LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error) {
+	_, integration := r.Resolve(task)
+	activities, activity, err := integration.LoadActivities(ctx, task)
+	if err != nil || activities == nil {
+		return activities, activity, err
+	}
+
+	if project := r.project(task); project != nil && project.DefaultActivityId != 0 {
+		for i := range *activities {
+			if (*activities)[i].Id == project.DefaultActivityId {
+				return activities, &(*activities)[i], nil
+			}
+		}
+	}
+	return activities, activity, nil
+}
+
+func (r *Router) SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error) {
+	_, integration := r.Resolve(task)
+	return integration.SendTask(ctx, r.Round(task), activityId)
+}
+
+func (r *Router) SetProjects(projects ProjectFinder) {
+	r.projects = projects
+}
+
+func (r *Router) project(task types.TasksToSync) *model.Project {
+	if r.projects == nil {
+		return nil
+	}
+	return r.projects.GetProject(task.Project)
+}
+
+func (r *Router) SetCache(cache types.Cache) {
+	for _, integration := range r.integrations {
+		if cacheable, ok := integration.(Cacheable); ok {
+			cacheable.SetCache(cache)
+		}
+	}
+}
+
+// Refresh discards the data cached by the integrations, so it is requested
+// again on the next load.
+func (r *Router) Refresh() {
+	for _, integration := range r.integrations {
+		if cacheable, ok := integration.(Cacheable); ok {
+			cacheable.Refresh()
+		}
+	}
+}
+
+// LoadCustomFields returns the custom fields of the integration a task is
+// routed to, which are none when the integration does not take them.
+func (r *Router) LoadCustomFields(ctx context.Context, task types.TasksToSync) ([]types.CustomField, error) {
+	_, integration := r.Resolve(task)
+	loader, ok := integration.(CustomFieldsLoader)
+	if !ok {
+		return nil, nil
+	}
+	return loader.LoadCustomFields(ctx, task)
+}
+
+func (r *Router) CanUpdateIssue(task types.TasksToSync) bool {
+	_, integration := r.Resolve(task)
+	_, ok := integration.(IssueUpdater)
+	return ok
+}
+
+func (r *Router) LoadIssueStatuses(ctx context.Context, task types.TasksToSync) ([]types.IssueStatus, *types.IssueStatus, int, error) {
+	_, integration := r.Resolve(task)
+	updater, ok := integration.(IssueUpdater)
+	if !ok {
+		return nil, nil, 0, ErrNotSupported
+	}
+	return updater.LoadIssueStatuses(ctx, task)
+}
+
+func (r *Router) UpdateIssue(ctx context.Context, task types.TasksToSync, update types.IssueUpdate) error {
+	_, integration := r.Resolve(task)
+	updater, ok := integration.(IssueUpdater)
+	if !ok {
+		return ErrNotSupported
+	}
+	return updater.UpdateIssue(ctx, task, update)
+}
+
+// ValidateId checks the external id against the integration the task would be
+// routed to.
+func (r *Router) ValidateId(ctx context.Context, externalId, project string) (*types.Issue, error) {
+	_, integration := r.Resolve(types.TasksToSync{ExternalId: externalId, Project: project})
+	validator, ok := integration.(Validator)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return validator.ValidateId(ctx, externalId, project)
+}
+
+// ListMyIssues lists the issues assigned to the user in the default
+// integration.
+func (r *Router) ListMyIssues(ctx context.Context, filter types.IssueFilter) ([]types.Issue, int, error) {
+	browser, ok := r.integrations[r.defaultName].(IssueBrowser)
+	if !ok {
+		return nil, 0, ErrNotSupported
+	}
+	return browser.ListMyIssues(ctx, filter)
+}
+
+func (r *Router) CanListMyIssues() bool {
+	_, ok := r.integrations[r.defaultName].(IssueBrowser)
+	return ok
+}
+
+// ListMyTimeEntries collects the remote time entries of every integration
+// able to list them. It also returns the names of those integrations, so the
+// caller knows which local tasks can be compared.
+func (r *Router) ListMyTimeEntries(ctx context.Context, from, to time.Time) ([]types.TimeEntry, map[string]bool, error) {
+	var result []types.TimeEntry
+	listed := map[string]bool{}
+
+	for name, integration := range r.integrations {
+		lister, ok := integration.(TimeEntryLister)
+		if !ok {
+			continue
+		}
+
+		entries, err := lister.ListMyTimeEntries(ctx, from, to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		for i := range entries {
+			entries[i].Integration = name
+		}
+		result = append(result, entries...)
+		listed[name] = true
+	}
+
+	if len(listed) == 0 {
+		return nil, nil, ErrNotSupported
+	}
+
+	return result, listed, nil
+}
+
+func (r *Router) CanListMyTimeEntries() bool {
+	for _, integration := range r.integrations {
+		if _, ok := integration.(TimeEntryLister); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconcile compares the local tasks between both dates with the time entries
+// recorded in the integrations they are routed to.
+func (r *Router) Reconcile(ctx context.Context, s *service.Service, from, to time.Time) ([]service.ReconcileRow, error) {
+	entries, listed, err := r.ListMyTimeEntries(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Reconcile(from, to, entries, reconcileLocator{router: r, listed: listed})
+}
+
+// reconcileLocator locates the tasks in the integration they are routed to,
+// leaving out those whose integration did not list its entries.
+type reconcileLocator struct {
+	router *Router
+	listed map[string]bool
+}
+
+func (l reconcileLocator) LocateTask(task model.Task) (service.ReconcileKey, bool) {
+	name, integration := l.router.Resolve(types.TasksToSync{ExternalId: *task.ExternalId, Project: task.ProjectName()})
+	if !l.listed[name] {
+		return service.ReconcileKey{}, false
+	}
+	return locateIssue(name, integration, *task.ExternalId, task.ProjectName()), true
+}
+
+func (l reconcileLocator) LocateEntry(entry types.TimeEntry) service.ReconcileKey {
+	return locateIssue(entry.Integration, l.router.integrations[entry.Integration], entry.ExternalId, "")
+}
+
+func locateIssue(name string, integration Integration, externalId, project string) service.ReconcileKey {
+	key := service.ReconcileKey{Integration: name, IssueId: strings.TrimSpace(externalId)}
+	if locator, ok := integration.(IssueLocator); ok {
+		if instance, issueId, err := locator.LocateIssue(key.IssueId, project); err == nil {
+			key.Instance, key.IssueId = instance, issueId
+		}
+	}
+	return key
+}
+
+func (r *Router) CanEditEntry(entry *model.RemoteEntry) bool {
+	_, ok := r.integrations[entry.Integration].(EntryEditor)
+	return ok
+}
+
+// AmendRemoteEntry brings a synced time entry in line with the local tasks
+// still linked to it: the entry is updated, moving it to their issue if their
+// external id was edited, or deleted when no task is left.
+func (r *Router) AmendRemoteEntry(ctx context.Context, s *service.Service, entry *model.RemoteEntry) error {
+	integration := r.integrations[entry.Integration]
+	editor, ok := integration.(EntryEditor)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	group, found, err := s.GetRemoteEntryGroup(entry)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		if err := editor.UpdateEntry(ctx, *entry, r.Round(group)); err != nil {
+			return err
+		}
+		key := locateIssue(entry.Integration, integration, group.ExternalId, group.Project)
+		if key.Instance == entry.Instance && key.IssueId == entry.IssueId {
+			return nil
+		}
+		return s.MoveRemoteEntry(entry, key.Instance, key.IssueId)
+	}
+
+	if err := editor.DeleteEntry(ctx, *entry); err != nil {
+		return err
+	}
+	return s.DeleteRemoteEntry(entry)
+}
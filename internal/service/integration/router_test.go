@@ -0,0 +1,295 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestRouterResolve(t *testing.T) {
+	config := `{
+		"url": "https://redmine.example.com",
+		"integrations": {
+			"jira": {"type": "webhook", "config": {"url": "https://jira.example.com"}},
+			"tools": {"type": "webhook", "config": {"url": "https://tools.example.com"}}
+		},
+		"routes": [
+			{"project": "Project B", "integration": "jira"},
+			{"external_id": "^TOOLS-\\d+$", "integration": "tools"}
+		]
+	}`
+
+	router, err := NewRouter(REDMINE, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		task     types.TasksToSync
+		expected string
+	}{
+		{types.TasksToSync{Project: "Project A", ExternalId: "1234"}, REDMINE},
+		{types.TasksToSync{Project: "project b", ExternalId: "1234"}, "jira"},
+		{types.TasksToSync{Project: "Project A", ExternalId: "TOOLS-12"}, "tools"},
+		{types.TasksToSync{Project: "Project B", ExternalId: "TOOLS-12"}, "jira"},
+	}
+
+	for _, test := range tests {
+		name, integration := router.Resolve(test.task)
+		if name != test.expected || integration == nil {
+			t.Errorf("Expected %v for %+v, got %v", test.expected, test.task, name)
+		}
+	}
+}
+
+type projectsMap map[string]*model.Project
+
+func (p projectsMap) GetProject(name string) *model.Project {
+	return p[name]
+}
+
+func TestRouterProjectDefaultIntegration(t *testing.T) {
+	config := `{
+		"url": "https://redmine.example.com",
+		"integrations": {"tools": {"type": "webhook", "config": {"url": "https://tools.example.com"}}},
+		"routes": [{"project": "Project A", "integration": "tools"}]
+	}`
+
+	router, err := NewRouter(REDMINE, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.SetProjects(projectsMap{
+		"Project A": {Name: "Project A", DefaultIntegration: REDMINE},
+		"Project B": {Name: "Project B", DefaultIntegration: "missing"},
+	})
+
+	if name, _ := router.Resolve(types.TasksToSync{Project: "Project A"}); name != REDMINE {
+		t.Errorf("Expected the project default integration, got %v", name)
+	}
+	if name, _ := router.Resolve(types.TasksToSync{Project: "Project B"}); name != REDMINE {
+		t.Errorf("Expected an unknown project integration to be ignored, got %v", name)
+	}
+}
+
+func TestRouterUnknownIntegration(t *testing.T) {
+	config := `{"routes": [{"project": "A", "integration": "missing"}]}`
+	if _, err := NewRouter(REDMINE, []byte(config)); err == nil {
+		t.Errorf("Expected error for a route to an unknown integration")
+	}
+}
+
+func TestRouterRound(t *testing.T) {
+	config := `{
+		"url": "https://redmine.example.com",
+		"rounding": {"mode": "up", "minutes": 15},
+		"project_rounding": {"Internal": {"mode": "none"}},
+		"integrations": {
+			"tools": {"type": "webhook", "config": {"url": "https://tools.example.com"}, "rounding": {"mode": "nearest", "minutes": 30}}
+		},
+		"routes": [{"external_id": "^TOOLS-\\d+$", "integration": "tools"}]
+	}`
+
+	router, err := NewRouter(REDMINE, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		task     types.TasksToSync
+		expected int
+	}{
+		{types.TasksToSync{ExternalId: "1234", Duration: 4000}, 4500},
+		{types.TasksToSync{ExternalId: "1234", Project: "internal", Duration: 4000}, 4000},
+		{types.TasksToSync{ExternalId: "TOOLS-1", Duration: 4000}, 3600},
+		{types.TasksToSync{ExternalId: "1234", Duration: 4500, RawDuration: 4000}, 4500},
+	}
+
+	for _, test := range tests {
+		task := router.Round(test.task)
+		if task.Duration != test.expected || task.RawDuration != 4000 {
+			t.Errorf("Expected %v from 4000 for %+v, got %v from %v", test.expected, test.task, task.Duration, task.RawDuration)
+		}
+
+		if again := router.Round(task); again.Duration != task.Duration {
+			t.Errorf("Expected rounding twice to keep %v, got %v", task.Duration, again.Duration)
+		}
+	}
+}
+
+func TestRouterInvalidRounding(t *testing.T) {
+	config := `{"rounding": {"mode": "up", "minutes": 10}}`
+	if _, err := NewRouter(REDMINE, []byte(config)); err == nil {
+		t.Errorf("Expected error for an invalid rounding")
+	}
+}
+
+func TestReconcileLocator(t *testing.T) {
+	config := `{
+		"url": "https://redmine.example.com",
+		"instances": [{"name": "acme", "url": "https://redmine.acme.com", "projects": ["Acme"]}],
+		"integrations": {"jira": {"type": "webhook", "config": {"url": "https://jira.example.com"}}},
+		"routes": [{"project": "Project B", "integration": "jira"}]
+	}`
+
+	router, err := NewRouter(REDMINE, []byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locator := reconcileLocator{router: router, listed: map[string]bool{REDMINE: true}}
+
+	task := func(externalId, project string) model.Task {
+		return model.Task{ExternalId: &externalId, Project: &project}
+	}
+
+	tests := []struct {
+		task     model.Task
+		entry    types.TimeEntry
+		expected service.ReconcileKey
+	}{
+		{task("1234", ""), types.TimeEntry{Integration: REDMINE, ExternalId: "1234"}, service.ReconcileKey{Integration: REDMINE, IssueId: "1234"}},
+		{task("1234", "acme"), types.TimeEntry{Integration: REDMINE, ExternalId: "acme:1234"}, service.ReconcileKey{Integration: REDMINE, Instance: "acme", IssueId: "1234"}},
+		{task(" acme:1234", "Other"), types.TimeEntry{Integration: REDMINE, ExternalId: "acme:1234"}, service.ReconcileKey{Integration: REDMINE, Instance: "acme", IssueId: "1234"}},
+	}
+
+	for _, test := range tests {
+		located, ok := locator.LocateTask(test.task)
+		if !ok || located != test.expected {
+			t.Errorf("task %s in %s: expected %+v, got %+v", *test.task.ExternalId, *test.task.Project, test.expected, located)
+		}
+		if located := locator.LocateEntry(test.entry); located != test.expected {
+			t.Errorf("entry %s: expected %+v, got %+v", test.entry.ExternalId, test.expected, located)
+		}
+	}
+
+	// jira can not list its entries
+	if located, ok := locator.LocateTask(task("1234", "Project B")); ok {
+		t.Errorf("expected the task sent to jira not to be compared, got %+v", located)
+	}
+}
+
+// editorStub is a stubIntegration able to amend its entries, whose "name:"
+// prefix is the instance.
+type editorStub struct {
+	*stubIntegration
+	updated, deleted []model.RemoteEntry
+	tasks            []types.TasksToSync
+}
+
+func (e *editorStub) UpdateEntry(ctx context.Context, entry model.RemoteEntry, task types.TasksToSync) error {
+	e.updated = append(e.updated, entry)
+	e.tasks = append(e.tasks, task)
+	return nil
+}
+
+func (e *editorStub) DeleteEntry(ctx context.Context, entry model.RemoteEntry) error {
+	e.deleted = append(e.deleted, entry)
+	return nil
+}
+
+func (e *editorStub) LocateIssue(externalId, project string) (string, string, error) {
+	if instance, issueId, found := strings.Cut(externalId, ":"); found {
+		return instance, issueId, nil
+	}
+	return "", externalId, nil
+}
+
+func TestAmendRemoteEntry(t *testing.T) {
+	router, stub, s := newOutboxTest(t)
+	ctx := context.Background()
+
+	task := s.GetTasksToSync()[0]
+	if err := router.Sync(ctx, s, task, 9); err != nil {
+		t.Fatal(err)
+	}
+	entry := s.GetRemoteEntry(1)
+	if err := router.AmendRemoteEntry(ctx, s, entry); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected the amend not supported, got %v", err)
+	}
+
+	editor := &editorStub{stubIntegration: stub}
+	router.integrations["stub"] = editor
+	issue := "acme:7"
+	if err := s.CreateTask("review", nil, &issue, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Repo.CloseOpenedTasks(); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Sync(ctx, s, s.GetTasksToSync()[0], 9); err != nil {
+		t.Fatal(err)
+	}
+	entry = s.GetRemoteEntry(3)
+	if entry == nil || entry.Integration != "stub" || entry.Instance != "acme" || entry.IssueId != "7" {
+		t.Fatalf("expected the entry synced to acme:7, got %+v", entry)
+	}
+
+	// the external id edited moves the entry to the issue
+	edited, _ := s.Repo.GetTask(3)
+	issue = "acme:8"
+	edited.ExternalId = &issue
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.AmendRemoteEntry(ctx, s, entry); err != nil {
+		t.Fatal(err)
+	}
+	if len(editor.updated) != 1 || editor.updated[0].IssueId != "7" || editor.tasks[0].ExternalId != "acme:8" {
+		t.Errorf("expected the entry of issue 7 updated with the edited task, got %+v %+v", editor.updated, editor.tasks)
+	}
+	if moved := s.GetRemoteEntry(3); moved.Instance != "acme" || moved.IssueId != "8" {
+		t.Errorf("expected the entry recorded in issue 8, got %+v", moved)
+	}
+
+	// deleted once no task is left
+	entry = s.GetRemoteEntry(3)
+	if err := s.DeleteTask(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.AmendRemoteEntry(ctx, s, entry); err != nil {
+		t.Fatal(err)
+	}
+	if len(editor.deleted) != 1 || editor.deleted[0].IssueId != "8" || editor.deleted[0].RemoteId != "42" {
+		t.Errorf("expected the entry of issue 8 deleted, got %+v", editor.deleted)
+	}
+}
+
+// validatorStub returns its issue, or fails with err when set.
+type validatorStub struct {
+	*stubIntegration
+	issue *types.Issue
+	err   error
+}
+
+func (v *validatorStub) ValidateId(ctx context.Context, externalId, project string) (*types.Issue, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	return v.issue, nil
+}
+
+func TestRouterValidateId(t *testing.T) {
+	ctx := context.Background()
+	validator := &validatorStub{stubIntegration: &stubIntegration{}, issue: &types.Issue{ExternalId: "123", Subject: "Fix the login"}}
+	router := &Router{defaultName: "stub", integrations: map[string]Integration{"stub": validator}}
+
+	issue, err := router.ValidateId(ctx, "123", "")
+	if err != nil || issue.Subject != "Fix the login" {
+		t.Fatalf("expected the issue validated, got %+v %v", issue, err)
+	}
+
+	validator.err = types.ErrNotFound
+	if issue, err := router.ValidateId(ctx, "123", ""); !errors.Is(err, types.ErrNotFound) || issue != nil {
+		t.Errorf("expected the issue not found, got %+v %v", issue, err)
+	}
+
+	router.integrations["stub"] = validator.stubIntegration
+	if _, err := router.ValidateId(ctx, "123", ""); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected the validation not supported, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// GetTaskNotes returns the notes of the given tasks having any, by task id.
+func (s *Service) GetTaskNotes(tasks []model.Task) (map[uint]string, error) {
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return s.Repo.GetTaskNotes(ids)
+}
+
+// GetNotes returns the notes of a task, empty when it has none.
+func (s *Service) GetNotes(taskId uint) (string, error) {
+	notes, err := s.Repo.GetTaskNotes([]uint{taskId})
+	if err != nil {
+		return "", err
+	}
+	return notes[taskId], nil
+}
+
+// SaveNotes replaces the notes of a task. Empty notes are removed.
+func (s *Service) SaveNotes(taskId uint, notes string) error {
+	return s.Repo.SaveTaskNotes(taskId, strings.TrimRight(notes, " \t\n"))
+}
+
+// withNotes sets the notes of the tasks of each group, joined by blank lines.
+func (s *Service) withNotes(groups []types.TasksToSync) []types.TasksToSync {
+	var ids []uint
+	for _, group := range groups {
+		ids = append(ids, groupIds(group)...)
+	}
+	if len(ids) == 0 {
+		return groups
+	}
+
+	notes, err := s.Repo.GetTaskNotes(ids)
+	if err != nil {
+		log.Println("Error loading task notes:", err)
+		return groups
+	}
+
+	for i := range groups {
+		var parts []string
+		for _, id := range groupIds(groups[i]) {
+			if note := notes[id]; note != "" {
+				parts = append(parts, note)
+			}
+		}
+		groups[i].Notes = strings.Join(parts, "\n\n")
+	}
+	return groups
+}
+
+func groupIds(group types.TasksToSync) []uint {
+	var ids []uint
+	for _, id := range group.Ids.IDs {
+		if parsed, err := strconv.ParseUint(id, 10, 0); err == nil {
+			ids = append(ids, uint(parsed))
+		}
+	}
+	return ids
+}
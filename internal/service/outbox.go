@@ -0,0 +1,146 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// ErrOutboxStale is returned for an outbox entry whose tasks are no longer a
+// group to sync: they were deleted, synced, or regrouped by editing them.
+var ErrOutboxStale = errors.New("the tasks of the outbox entry changed")
+
+func (s *Service) GetOutbox() ([]model.OutboxEntry, error) {
+	return s.Repo.GetOutboxEntries()
+}
+
+// AddToOutbox stores a sync group to be sent later. A group already in the
+// outbox gets its attempts and last error updated instead. A nil error means
+// the group has been deferred by the user.
+func (s *Service) AddToOutbox(task types.TasksToSync, integration string, activityId int, sendErr error) error {
+	payload, err := json.Marshal(types.OutboxPayload{
+		TaskIds:      task.Ids.IDs,
+		ActivityId:   activityId,
+		CustomFields: task.CustomFields,
+	})
+	if err != nil {
+		return err
+	}
+
+	entry, err := s.outboxEntry(task)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		entry = &model.OutboxEntry{}
+	}
+
+	entry.TaskIds = strings.Join(task.Ids.IDs, ",")
+	entry.Integration = integration
+	entry.Payload = string(payload)
+	if sendErr != nil {
+		entry.Attempts++
+		entry.LastError = sendErr.Error()
+	}
+
+	return s.Repo.SaveOutboxEntry(entry)
+}
+
+// OutboxPayload decodes what is stored in an outbox entry.
+func (s *Service) OutboxPayload(entry model.OutboxEntry) (types.OutboxPayload, error) {
+	var payload types.OutboxPayload
+	err := json.Unmarshal([]byte(entry.Payload), &payload)
+	return payload, err
+}
+
+// OutboxTask builds the sync group of an outbox entry from its tasks as they
+// are now, so the changes made since it was queued are sent. It fails with
+// ErrOutboxStale when they are no longer one group to sync.
+func (s *Service) OutboxTask(payload types.OutboxPayload) (types.TasksToSync, error) {
+	tasks, err := s.Repo.GetTasksToSync()
+	if err != nil {
+		return types.TasksToSync{}, err
+	}
+
+	groups := slices.DeleteFunc(tasks, func(task types.TasksToSync) bool {
+		return !slices.ContainsFunc(task.Ids.IDs, func(id string) bool { return slices.Contains(payload.TaskIds, id) })
+	})
+
+	switch len(groups) {
+	case 0:
+		return types.TasksToSync{}, fmt.Errorf("%w: they were deleted or synced", ErrOutboxStale)
+	case 1:
+		task := s.withNotes(groups)[0]
+		task.CustomFields = payload.CustomFields
+		return task, nil
+	default:
+		return types.TasksToSync{}, fmt.Errorf("%w: they are now in %d groups", ErrOutboxStale, len(groups))
+	}
+}
+
+func (s *Service) DiscardOutboxEntry(entry model.OutboxEntry) error {
+	return s.Repo.DeleteOutboxEntry(entry.ID)
+}
+
+// MarkAsSynced flags the tasks of a group as reported, links them to the
+// remote entry created, if it has a remote id, and removes the group from the
+// outbox.
+func (s *Service) MarkAsSynced(task types.TasksToSync, remote model.RemoteEntry) error {
+	for _, idStr := range task.Ids.IDs {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return err
+		}
+		if err := s.Repo.SetTaskAsReported(uint(id)); err != nil {
+			return err
+		}
+	}
+
+	if remote.RemoteId != "" {
+		if err := s.SaveRemoteEntry(task, remote); err != nil {
+			return err
+		}
+	}
+
+	entry, err := s.outboxEntry(task)
+	if err != nil || entry == nil {
+		return err
+	}
+	return s.Repo.DeleteOutboxEntry(entry.ID)
+}
+
+// outboxEntry returns the entry queuing any of the tasks of the group, which
+// may have gained or lost tasks since.
+func (s *Service) outboxEntry(task types.TasksToSync) (*model.OutboxEntry, error) {
+	entries, err := s.Repo.GetOutboxEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		queued := strings.Split(entry.TaskIds, ",")
+		if slices.ContainsFunc(task.Ids.IDs, func(id string) bool { return slices.Contains(queued, id) }) {
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// withoutOutbox removes the groups with any task already in the outbox, as
+// they are sent from there.
+func withoutOutbox(tasks []types.TasksToSync, entries []model.OutboxEntry) []types.TasksToSync {
+	var queued []string
+	for _, entry := range entries {
+		queued = append(queued, strings.Split(entry.TaskIds, ",")...)
+	}
+
+	return slices.DeleteFunc(tasks, func(task types.TasksToSync) bool {
+		return slices.ContainsFunc(task.Ids.IDs, func(id string) bool { return slices.Contains(queued, id) })
+	})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/repository"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// createSyncGroup creates two tasks of an hour, grouped to sync, and an
+// hour of another issue.
+func createSyncGroup(t *testing.T, s *Service, clock *repository.FakeClock) []types.TasksToSync {
+	t.Helper()
+	review, other := "ISSUE-1", "ISSUE-2"
+	for _, issue := range []*string{&review, &other, &review} {
+		if err := s.CreateTask("review", nil, issue, nil); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Hour)
+	}
+	if err := s.Repo.CloseOpenedTasks(); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := s.GetTasksToSync()
+	if len(tasks) != 2 || tasks[0].ExternalId != "ISSUE-1" || tasks[0].Duration != 7200 {
+		t.Fatalf("expected ISSUE-1 grouped, got %+v", tasks)
+	}
+	return tasks
+}
+
+func TestAddToOutbox(t *testing.T) {
+	s, clock := newTestService(t)
+	task := createSyncGroup(t, s, clock)[0]
+	task.CustomFields = []types.CustomFieldValue{{Id: 4, Value: "1"}}
+
+	if err := s.AddToOutbox(task, "redmine", 9, errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddToOutbox(task, "redmine", 9, errors.New("bad gateway")); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox, err := s.GetOutbox()
+	if err != nil || len(outbox) != 1 {
+		t.Fatalf("expected a single entry, got %+v %v", outbox, err)
+	}
+	entry := outbox[0]
+	if entry.TaskIds != "1,3" || entry.Attempts != 2 || entry.LastError != "bad gateway" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if strings.Contains(entry.Payload, "review") {
+		t.Errorf("expected no copy of the tasks in the payload, got %s", entry.Payload)
+	}
+
+	payload, err := s.OutboxPayload(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(payload.TaskIds, ",") != "1,3" || payload.ActivityId != 9 || len(payload.CustomFields) != 1 {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	if remaining := s.GetTasksToSync(); len(remaining) != 1 || remaining[0].ExternalId != "ISSUE-2" {
+		t.Errorf("expected the queued group to be left out, got %+v", remaining)
+	}
+}
+
+func TestOutboxTask(t *testing.T) {
+	s, clock := newTestService(t)
+	task := createSyncGroup(t, s, clock)[0]
+	if err := s.AddToOutbox(task, "redmine", 9, nil); err != nil {
+		t.Fatal(err)
+	}
+	outbox, _ := s.GetOutbox()
+	payload, err := s.OutboxPayload(outbox[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the changes made after queuing the group are sent
+	edited, err := s.Repo.GetTask(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited.Desc = "review #api"
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+	edited, _ = s.Repo.GetTask(3)
+	edited.Desc = "review #api"
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveNotes(3, "found the bug"); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := s.OutboxTask(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Desc != "review #api" || current.Duration != 7200 || current.Notes != "found the bug" {
+		t.Errorf("expected the edited group, got %+v", current)
+	}
+
+	// a task deleted leaves the others
+	if err := s.DeleteTask(1); err != nil {
+		t.Fatal(err)
+	}
+	current, err = s.OutboxTask(payload)
+	if err != nil || strings.Join(current.Ids.IDs, ",") != "3" || current.Duration != 3600 {
+		t.Errorf("expected the group without the deleted task, got %+v %v", current, err)
+	}
+
+	if err := s.DeleteTask(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.OutboxTask(payload); !errors.Is(err, ErrOutboxStale) {
+		t.Errorf("expected a stale entry once its tasks are deleted, got %v", err)
+	}
+}
+
+func TestOutboxTaskRegrouped(t *testing.T) {
+	s, clock := newTestService(t)
+	task := createSyncGroup(t, s, clock)[0]
+
+	edited, _ := s.Repo.GetTask(3)
+	edited.Desc = "something else"
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.OutboxTask(types.OutboxPayload{TaskIds: task.Ids.IDs})
+	if !errors.Is(err, ErrOutboxStale) {
+		t.Errorf("expected a stale entry once its tasks are split, got %v", err)
+	}
+}
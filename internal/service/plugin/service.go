@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const DEFAULT_TIMEOUT = 60
+
+type Plugin struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	Timeout int               `json:"timeout"`
+}
+
+func NewPlugin(config []byte) (*Plugin, error) {
+	var plugin Plugin
+	if err := json.Unmarshal(config, &plugin); err != nil {
+		return nil, err
+	}
+
+	if plugin.Command == "" {
+		return nil, fmt.Errorf("plugin command is required")
+	}
+
+	if plugin.Timeout <= 0 {
+		plugin.Timeout = DEFAULT_TIMEOUT
+	}
+
+	return &plugin, nil
+}
+
+func (p *Plugin) ValidateId(ctx context.Context, externalId, project string) (*types.Issue, error) {
+	response, err := p.call(ctx, PluginRequest{Operation: OP_VALIDATE, ExternalId: externalId, Project: project})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Issue == nil {
+		return &types.Issue{ExternalId: externalId}, nil
+	}
+	return response.Issue, nil
+}
+
+func (p *Plugin) LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error) {
+	response, err := p.call(ctx, PluginRequest{
+		Operation:  OP_ACTIVITIES,
+		ExternalId: task.ExternalId,
+		Task:       newPluginTask(task),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var defaultActivity types.Activity
+	activities := response.Activities
+	for _, activity := range activities {
+		if activity.Id == response.DefaultActivity {
+			defaultActivity = activity
+		}
+	}
+
+	return &activities, &defaultActivity, nil
+}
+
+func (p *Plugin) SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error) {
+	response, err := p.call(ctx, newSendRequest(task, activityId))
+	if err != nil {
+		return "", err
+	}
+	return response.Id, nil
+}
+
+// PreviewTask returns the command line and the JSON written to the plugin
+// stdin to send the task group.
+func (p *Plugin) PreviewTask(task types.TasksToSync, activityId int) (*types.RequestPreview, error) {
+	input, err := json.Marshal(newSendRequest(task, activityId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RequestPreview{
+		Method: "exec",
+		Url:    strings.Join(append([]string{p.Command}, p.Args...), " "),
+		Body:   string(input),
+	}, nil
+}
+
+func (p *Plugin) call(ctx context.Context, request PluginRequest) (*PluginResponse, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.Timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = os.Environ()
+	for key, value := range p.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Println("Error running plugin:", err, strings.TrimSpace(stderr.String()))
+		return nil, fmt.Errorf("plugin %s failed: %w", request.Operation, err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		log.Println("Error unmarshalling plugin response:", err, stdout.String())
+		return nil, fmt.Errorf("invalid plugin response: %w", err)
+	}
+
+	if !response.Ok {
+		if response.Error == "" {
+			response.Error = "unknown error"
+		}
+		return nil, fmt.Errorf("plugin %s failed: %s", request.Operation, response.Error)
+	}
+
+	return &response, nil
+}
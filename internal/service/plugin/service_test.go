@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// TestHelperProcess is not a real test: it is run as the plugin executable by
+// the other tests in this file.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("MYTIME_PLUGIN_HELPER") != "1" {
+		return
+	}
+
+	var request PluginRequest
+	json.NewDecoder(os.Stdin).Decode(&request)
+
+	switch request.Operation {
+	case OP_VALIDATE:
+		fmt.Printf(`{"ok": true, "issue": {"external_id": %q, "subject": "Subject"}}`, request.ExternalId)
+	case OP_ACTIVITIES:
+		fmt.Print(`{"ok": true, "activities": [{"id": 1, "name": "Dev"}, {"id": 2, "name": "Meeting"}], "default_activity": 2}`)
+	case OP_SEND:
+		if request.Task.Duration == 0 || request.ActivityId == 0 {
+			fmt.Print(`{"ok": false, "error": "missing data"}`)
+		} else {
+			fmt.Print(`{"ok": true, "id": "99"}`)
+		}
+	}
+	os.Exit(0)
+}
+
+func newHelperPlugin() *Plugin {
+	return &Plugin{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperProcess"},
+		Env:     map[string]string{"MYTIME_PLUGIN_HELPER": "1"},
+		Timeout: DEFAULT_TIMEOUT,
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	plugin := newHelperPlugin()
+
+	issue, err := plugin.ValidateId(context.Background(), "123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.ExternalId != "123" || issue.Subject != "Subject" {
+		t.Errorf("Unexpected issue %+v", issue)
+	}
+
+	activities, defaultActivity, err := plugin.LoadActivities(context.Background(), types.TasksToSync{ExternalId: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*activities) != 2 || defaultActivity.Name != "Meeting" {
+		t.Errorf("Unexpected activities %+v, default %+v", *activities, defaultActivity)
+	}
+
+	remoteId, err := plugin.SendTask(context.Background(), types.TasksToSync{ExternalId: "123", Duration: 60}, 1)
+	if err != nil || remoteId != "99" {
+		t.Errorf("Expected remote id 99, got %v (%v)", remoteId, err)
+	}
+
+	if _, err := plugin.SendTask(context.Background(), types.TasksToSync{ExternalId: "123"}, 1); err == nil {
+		t.Errorf("Expected error when the plugin answers ok=false")
+	}
+}
@@ -0,0 +1,65 @@
+package plugin
+
+import "github.com/francescarpi/mytime/internal/types"
+
+const (
+	OP_VALIDATE   = "validate"
+	OP_ACTIVITIES = "activities"
+	OP_SEND       = "send"
+)
+
+type PluginTask struct {
+	Id           string                   `json:"id"`
+	ExternalId   string                   `json:"external_id"`
+	Duration     int                      `json:"duration"`
+	RawDuration  int                      `json:"raw_duration"`
+	Desc         string                   `json:"desc"`
+	Date         string                   `json:"date"`
+	Project      string                   `json:"project"`
+	Ids          []string                 `json:"ids"`
+	CustomFields []types.CustomFieldValue `json:"custom_fields,omitempty"`
+	Notes        string                   `json:"notes,omitempty"`
+}
+
+// PluginRequest is written as a single JSON document to the plugin stdin.
+type PluginRequest struct {
+	Operation  string      `json:"operation"`
+	ExternalId string      `json:"external_id,omitempty"`
+	Project    string      `json:"project,omitempty"`
+	Task       *PluginTask `json:"task,omitempty"`
+	ActivityId int         `json:"activity_id,omitempty"`
+}
+
+// PluginResponse is read as a single JSON document from the plugin stdout.
+type PluginResponse struct {
+	Ok              bool             `json:"ok"`
+	Error           string           `json:"error"`
+	Id              string           `json:"id"`
+	Issue           *types.Issue     `json:"issue"`
+	Activities      []types.Activity `json:"activities"`
+	DefaultActivity int              `json:"default_activity"`
+}
+
+func newPluginTask(task types.TasksToSync) *PluginTask {
+	return &PluginTask{
+		Id:           task.Id,
+		ExternalId:   task.ExternalId,
+		Duration:     task.Duration,
+		RawDuration:  task.RawDuration,
+		Desc:         task.Desc,
+		Date:         task.Date,
+		Project:      task.Project,
+		Ids:          task.Ids.IDs,
+		CustomFields: task.CustomFields,
+		Notes:        task.Notes,
+	}
+}
+
+func newSendRequest(task types.TasksToSync, activityId int) PluginRequest {
+	return PluginRequest{
+		Operation:  OP_SEND,
+		ExternalId: task.ExternalId,
+		Task:       newPluginTask(task),
+		ActivityId: activityId,
+	}
+}
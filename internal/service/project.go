@@ -0,0 +1,33 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/model"
+)
+
+func (s *Service) GetProjects() ([]model.Project, error) {
+	return s.Repo.GetProjects()
+}
+
+// GetProject returns the project of the given name, or nil when there is none.
+func (s *Service) GetProject(name string) *model.Project {
+	if name == "" {
+		return nil
+	}
+
+	project, err := s.Repo.GetProject(name)
+	if err != nil {
+		return nil
+	}
+	return project
+}
+
+// SaveProject creates or updates a project. The tasks of a renamed project are
+// tracked as a single change, so the rename can be undone.
+func (s *Service) SaveProject(project *model.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	return s.track(func() error {
+		return s.Repo.SaveProject(project)
+	})
+}
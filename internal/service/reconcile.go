@@ -0,0 +1,163 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// Durations closer than this are considered the same, to absorb the rounding
+// integrations apply to hours.
+const RECONCILE_TOLERANCE = 60
+
+type ReconcileStatus int
+
+const (
+	ReconcileOk ReconcileStatus = iota
+	ReconcileMissingRemote
+	ReconcileMissingLocal
+	ReconcileMismatch
+	ReconcileReportedMissingRemote
+)
+
+func (s ReconcileStatus) String() string {
+	switch s {
+	case ReconcileMissingRemote:
+		return "Missing remotely"
+	case ReconcileMissingLocal:
+		return "Missing locally"
+	case ReconcileMismatch:
+		return "Duration mismatch"
+	case ReconcileReportedMissingRemote:
+		return "Reported, missing remotely"
+	}
+	return "Ok"
+}
+
+type ReconcileRow struct {
+	Date        string
+	Integration string
+	ExternalId  string
+	Local       int
+	Reported    int
+	Remote      int
+	Status      ReconcileStatus
+}
+
+// ReconcileKey is where a task or a time entry is recorded: the integration,
+// its instance, if it has more than one server, and the issue id there, with
+// no instance prefix.
+type ReconcileKey struct {
+	Integration string
+	Instance    string
+	IssueId     string
+}
+
+// ExternalId returns the issue id prefixed with its instance.
+func (k ReconcileKey) ExternalId() string {
+	if k.Instance == "" {
+		return k.IssueId
+	}
+	return k.Instance + ":" + k.IssueId
+}
+
+// ReconcileLocator tells where the local tasks and the remote entries are
+// recorded, so the ones of the same issue are compared whatever the form of
+// their external id. LocateTask returns false for the tasks not to compare,
+// as their integration can not list its entries.
+type ReconcileLocator interface {
+	LocateTask(task model.Task) (ReconcileKey, bool)
+	LocateEntry(entry types.TimeEntry) ReconcileKey
+}
+
+// Reconcile compares the local tasks between both dates with the time entries
+// recorded remotely, per day and issue.
+func (s *Service) Reconcile(from, to time.Time, entries []types.TimeEntry, locator ReconcileLocator) ([]ReconcileRow, error) {
+	tasks, err := s.Repo.GetTasksBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var compared []model.Task
+	for _, task := range tasks {
+		if task.ExternalId != nil && *task.ExternalId != "" {
+			compared = append(compared, task)
+		}
+	}
+
+	return reconcile(compared, entries, locator), nil
+}
+
+func reconcile(tasks []model.Task, entries []types.TimeEntry, locator ReconcileLocator) []ReconcileRow {
+	type key struct {
+		date string
+		ReconcileKey
+	}
+	rows := map[key]*ReconcileRow{}
+
+	row := func(k key) *ReconcileRow {
+		if _, ok := rows[k]; !ok {
+			rows[k] = &ReconcileRow{Date: k.date, Integration: k.Integration, ExternalId: k.ExternalId()}
+		}
+		return rows[k]
+	}
+
+	for _, task := range tasks {
+		located, ok := locator.LocateTask(task)
+		if !ok {
+			continue
+		}
+		r := row(key{task.Start.Format(time.DateOnly), located})
+		r.Local += task.Duration
+		if task.Reported {
+			r.Reported += task.Duration
+		}
+	}
+
+	for _, entry := range entries {
+		r := row(key{entry.Date, locator.LocateEntry(entry)})
+		r.Remote += entry.Duration
+	}
+
+	result := make([]ReconcileRow, 0, len(rows))
+	for _, r := range rows {
+		expected := r.Reported
+		if expected == 0 {
+			expected = r.Local
+		}
+
+		switch {
+		case r.Remote == 0 && r.Reported > 0:
+			r.Status = ReconcileReportedMissingRemote
+		case r.Remote == 0:
+			r.Status = ReconcileMissingRemote
+		case r.Local == 0:
+			r.Status = ReconcileMissingLocal
+		case abs(r.Remote-expected) > RECONCILE_TOLERANCE:
+			r.Status = ReconcileMismatch
+		default:
+			r.Status = ReconcileOk
+		}
+		result = append(result, *r)
+	}
+
+	slices.SortFunc(result, func(a, b ReconcileRow) int {
+		return cmp.Or(
+			cmp.Compare(b.Date, a.Date),
+			cmp.Compare(a.Integration, b.Integration),
+			cmp.Compare(a.ExternalId, b.ExternalId),
+		)
+	})
+
+	return result
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// fakeLocator routes the tasks of the "jira" project to jira and the others to
+// redmine, where the "acme" project and the "acme:" prefix go to the acme
+// instance. The tasks of the "offline" project are not compared.
+type fakeLocator struct{}
+
+func (fakeLocator) LocateTask(task model.Task) (ReconcileKey, bool) {
+	switch task.ProjectName() {
+	case "offline":
+		return ReconcileKey{}, false
+	case "jira":
+		return ReconcileKey{Integration: "jira", IssueId: *task.ExternalId}, true
+	}
+	key := fakeLocator{}.LocateEntry(types.TimeEntry{Integration: "redmine", ExternalId: *task.ExternalId})
+	if task.ProjectName() == "acme" {
+		key.Instance = "acme"
+	}
+	return key, true
+}
+
+func (fakeLocator) LocateEntry(entry types.TimeEntry) ReconcileKey {
+	key := ReconcileKey{Integration: entry.Integration, IssueId: entry.ExternalId}
+	if instance, issueId, ok := strings.Cut(entry.ExternalId, ":"); ok {
+		key.Instance, key.IssueId = instance, issueId
+	}
+	return key
+}
+
+func reconcileTask(date, externalId, project string, duration int, reported bool) model.Task {
+	start, _ := time.ParseInLocation(time.DateOnly, date, time.Local)
+	task := model.Task{
+		Start:      model.LocalTimestamp{Time: start},
+		ExternalId: &externalId,
+		Duration:   duration,
+		Reported:   reported,
+	}
+	if project != "" {
+		task.Project = &project
+	}
+	return task
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name     string
+		tasks    []model.Task
+		entries  []types.TimeEntry
+		expected []ReconcileRow
+	}{
+		{
+			name: "matching within the tolerance",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "", 3600, true),
+				reconcileTask("2025-02-26", "123", "", 1800, true),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "123", Date: "2025-02-26", Duration: 5430},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "123", Local: 5400, Reported: 5400, Remote: 5430, Status: ReconcileOk},
+			},
+		},
+		{
+			name: "mismatch",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "", 3600, true),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "123", Date: "2025-02-26", Duration: 1800},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "123", Local: 3600, Reported: 3600, Remote: 1800, Status: ReconcileMismatch},
+			},
+		},
+		{
+			name: "compared with the local duration until reported",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "", 3600, false),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "123", Date: "2025-02-26", Duration: 3600},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "123", Local: 3600, Remote: 3600, Status: ReconcileOk},
+			},
+		},
+		{
+			name: "missing on either side",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "", 3600, false),
+				reconcileTask("2025-02-25", "123", "", 3600, true),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "456", Date: "2025-02-26", Duration: 1800},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "123", Local: 3600, Status: ReconcileMissingRemote},
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "456", Remote: 1800, Status: ReconcileMissingLocal},
+				{Date: "2025-02-25", Integration: "redmine", ExternalId: "123", Local: 3600, Reported: 3600, Status: ReconcileReportedMissingRemote},
+			},
+		},
+		{
+			name: "named instance reached through the project",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "acme", 3600, true),
+				reconcileTask("2025-02-26", "acme:123", "", 1800, true),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "acme:123", Date: "2025-02-26", Duration: 5400},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "acme:123", Local: 5400, Reported: 5400, Remote: 5400, Status: ReconcileOk},
+			},
+		},
+		{
+			name: "same issue number in other instances and integrations",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "", 3600, true),
+				reconcileTask("2025-02-26", "123", "acme", 1800, true),
+				reconcileTask("2025-02-26", "123", "jira", 900, true),
+			},
+			entries: []types.TimeEntry{
+				{Integration: "redmine", ExternalId: "123", Date: "2025-02-26", Duration: 3600},
+				{Integration: "redmine", ExternalId: "acme:123", Date: "2025-02-26", Duration: 1800},
+				{Integration: "jira", ExternalId: "123", Date: "2025-02-26", Duration: 900},
+			},
+			expected: []ReconcileRow{
+				{Date: "2025-02-26", Integration: "jira", ExternalId: "123", Local: 900, Reported: 900, Remote: 900, Status: ReconcileOk},
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "123", Local: 3600, Reported: 3600, Remote: 3600, Status: ReconcileOk},
+				{Date: "2025-02-26", Integration: "redmine", ExternalId: "acme:123", Local: 1800, Reported: 1800, Remote: 1800, Status: ReconcileOk},
+			},
+		},
+		{
+			name: "tasks not compared",
+			tasks: []model.Task{
+				reconcileTask("2025-02-26", "123", "offline", 3600, true),
+			},
+			expected: []ReconcileRow{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows := reconcile(test.tasks, test.entries, fakeLocator{})
+			if !slices.Equal(rows, test.expected) {
+				t.Errorf("expected\n%+v\ngot\n%+v", test.expected, rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package redmine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const DEFAULT_CACHE_TTL = 24 * 60 * 60
+
+// cache keeps issues and project activities in the local cache for the
+// configured TTL, and makes concurrent requests for the same key wait for the
+// first one instead of hitting the server again.
+type cache struct {
+	store     types.Cache
+	ttl       time.Duration
+	mu        sync.Mutex
+	refreshed time.Time
+	calls     map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+func newCache(ttl int) *cache {
+	if ttl <= 0 {
+		ttl = DEFAULT_CACHE_TTL
+	}
+
+	return &cache{
+		ttl:   time.Duration(ttl) * time.Second,
+		calls: map[string]*call{},
+	}
+}
+
+func (c *cache) refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshed = time.Now()
+}
+
+// fresh returns the cached value of the key when it has not expired and it was
+// stored after the last refresh.
+func (c *cache) fresh(key string) []byte {
+	if c.store == nil {
+		return nil
+	}
+
+	entry, err := c.store.GetCacheEntry(key)
+	if err != nil {
+		return nil
+	}
+
+	updatedAt := localTime(entry.UpdatedAt.Time)
+
+	c.mu.Lock()
+	refreshed := c.refreshed
+	c.mu.Unlock()
+
+	if time.Since(updatedAt) > c.ttl || updatedAt.Before(refreshed) {
+		return nil
+	}
+	return []byte(entry.Value)
+}
+
+func (c *cache) load(key string, fetch func() (any, error)) ([]byte, error) {
+	if value := c.fresh(key); value != nil {
+		return value, nil
+	}
+
+	c.mu.Lock()
+	if current, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		<-current.done
+		return current.value, current.err
+	}
+
+	current := &call{done: make(chan struct{})}
+	c.calls[key] = current
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(current.done)
+	}()
+
+	result, err := fetch()
+	if err != nil {
+		current.err = err
+		return nil, err
+	}
+
+	current.value, current.err = json.Marshal(result)
+	if current.err != nil {
+		return nil, current.err
+	}
+
+	if c.store != nil {
+		if err := c.store.SaveCacheEntry(key, string(current.value)); err != nil {
+			log.Println("Error caching", key, err)
+		}
+	}
+
+	return current.value, nil
+}
+
+// cached returns the value of the key from the cache, or fetches it and
+// stores it when it is missing or has expired.
+func cached[T any](ctx context.Context, c *cache, key string, fetch func(ctx context.Context) (*T, error)) (*T, error) {
+	value, err := c.load(key, func() (any, error) { return fetch(ctx) })
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.Unmarshal(value, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// stale returns the value of the key left in the cache, however old, for when
+// it can not be fetched again.
+func stale[T any](c *cache, key string) *T {
+	if c.store == nil {
+		return nil
+	}
+
+	entry, err := c.store.GetCacheEntry(key)
+	if err != nil {
+		return nil
+	}
+
+	var result T
+	if err := json.Unmarshal([]byte(entry.Value), &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// localTime interprets a timestamp read from the database, where it is stored
+// as the local wall clock time, in the local time zone.
+func localTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
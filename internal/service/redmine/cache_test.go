@@ -0,0 +1,131 @@
+package redmine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+	"gorm.io/gorm"
+)
+
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]model.CacheEntry
+}
+
+func (c *memoryCache) GetCacheEntry(key string) (*model.CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (c *memoryCache) SaveCacheEntry(key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = model.CacheEntry{Key: key, Value: value, UpdatedAt: model.LocalTimestamp{Time: time.Now()}}
+	return nil
+}
+
+func TestLoadActivitiesCache(t *testing.T) {
+	var issueCalls, projectCalls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/projects/") {
+			projectCalls.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(`{"project": {"id": 7, "time_entry_activities": [{"id": 9, "name": "Development"}]}}`))
+			return
+		}
+		issueCalls.Add(1)
+		w.Write([]byte(`{"issue": {"id": 1, "project": {"id": 7, "name": "Portal"}}}`))
+	}))
+	defer server.Close()
+
+	redmine, err := NewRedmine([]byte(`{"url": "` + server.URL + `", "default_activity": 9}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	redmine.SetCache(&memoryCache{entries: map[string]model.CacheEntry{}})
+
+	load := func() {
+		var wg sync.WaitGroup
+		for _, externalId := range []string{"1", "2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, defaultActivity, err := redmine.LoadActivities(context.Background(), types.TasksToSync{ExternalId: externalId})
+				if err != nil || defaultActivity.Name != "Development" {
+					t.Errorf("Expected default activity Development, got %v (%v)", defaultActivity, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	load()
+	if issueCalls.Load() != 2 || projectCalls.Load() != 1 {
+		t.Errorf("Expected 2 issue and 1 project requests, got %v and %v", issueCalls.Load(), projectCalls.Load())
+	}
+
+	load()
+	if issueCalls.Load() != 2 || projectCalls.Load() != 1 {
+		t.Errorf("Expected cached activities, got %v issue and %v project requests", issueCalls.Load(), projectCalls.Load())
+	}
+
+	redmine.Refresh()
+	load()
+	if issueCalls.Load() != 4 || projectCalls.Load() != 2 {
+		t.Errorf("Expected 4 issue and 2 project requests after refresh, got %v and %v", issueCalls.Load(), projectCalls.Load())
+	}
+}
+
+func TestValidateIdCachedCopy(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		w.Write([]byte(`{"issue": {"id": 1, "subject": "Fix the login", "project": {"id": 7, "name": "Portal"}}}`))
+	}))
+	defer server.Close()
+
+	redmine := newTestRedmine(t, `{"url": "`+server.URL+`"}`)
+	redmine.SetCache(&memoryCache{entries: map[string]model.CacheEntry{}})
+
+	issue, err := redmine.ValidateId(context.Background(), "1", "")
+	if err != nil || issue.Subject != "Fix the login" || issue.Cached {
+		t.Fatalf("expected the issue validated, got %+v %v", issue, err)
+	}
+
+	// the server fails, so the copy left in the cache is returned
+	redmine.Refresh()
+	status.Store(http.StatusForbidden)
+	issue, err = redmine.ValidateId(context.Background(), "1", "")
+	if err != nil || issue.Subject != "Fix the login" || !issue.Cached {
+		t.Errorf("expected the cached issue, got %+v %v", issue, err)
+	}
+	if _, err := redmine.ValidateId(context.Background(), "2", ""); !errors.Is(err, types.ErrForbidden) {
+		t.Errorf("expected the error for an issue not cached, got %v", err)
+	}
+
+	// an issue not found is not taken from the cache
+	status.Store(http.StatusNotFound)
+	if issue, err := redmine.ValidateId(context.Background(), "1", ""); !errors.Is(err, types.ErrNotFound) || issue != nil {
+		t.Errorf("expected the issue not found, got %+v %v", issue, err)
+	}
+}
@@ -1,74 +1,199 @@
 package redmine
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const (
+	DEFAULT_TIMEOUT = 30
+	DEFAULT_RETRIES = 3
+	RETRY_BACKOFF   = 500 * time.Millisecond
 )
 
-func RequestGET[T any](token, url string) (*T, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// Client is shared by all the requests sent to a Redmine instance. Every
+// request has a timeout, and idempotent ones are retried when the server is
+// failing or rate limiting.
+type Client struct {
+	http    *http.Client
+	retries int
+}
+
+func NewClient(timeout, retries int) *Client {
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+	if retries < 0 {
+		retries = 0
+	}
+
+	return &Client{
+		http:    &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		retries: retries,
+	}
+}
+
+func (c *Client) request(ctx context.Context, method, token, url string, rqBody []byte) ([]byte, error) {
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.retries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		body, retryAfter, err := c.do(ctx, method, token, url, rqBody)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if !retryable(err) || attempt == attempts-1 {
+			break
+		}
+
+		wait := RETRY_BACKOFF << attempt
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		log.Printf("Retrying %s %s in %s: %s", method, url, wait, err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (c *Client) do(ctx context.Context, method, token, url string, rqBody []byte) ([]byte, time.Duration, error) {
+	var reader io.Reader
+	if rqBody != nil {
+		reader = bytes.NewReader(rqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		log.Println("Error creating request:", err)
+		return nil, 0, err
+	}
+
 	req.Header.Set("X-Redmine-API-Key", token)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
-		log.Println("Error fetching issue:", err)
-		return nil, err
+		log.Println("Error sending request:", err)
+		return nil, 0, err
 	}
 
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Println("Error reading response body:", err)
-		return nil, err
+		return nil, 0, err
 	}
 
-	var result T
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		log.Println("Error unmarshalling response:", err)
-		return nil, err
+	if err := checkStatus(resp.StatusCode, body); err != nil {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return nil, time.Duration(retryAfter) * time.Second, err
 	}
 
-	return &result, nil
+	return body, 0, nil
 }
 
-func RequestPOST[T any](token, url string, rqBody io.Reader) (*T, error) {
-	req, err := http.NewRequest("POST", url, rqBody)
-	req.Header.Set("X-Redmine-API-Key", token)
-	req.Header.Set("Content-Type", "application/json")
+// StatusError is returned for unexpected status codes without a more specific
+// error.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func checkStatus(status int, body []byte) error {
+	switch {
+	case status >= 200 && status <= 299:
+		return nil
+	case status == http.StatusUnauthorized:
+		return types.ErrUnauthorized
+	case status == http.StatusForbidden:
+		return types.ErrForbidden
+	case status == http.StatusNotFound:
+		return types.ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return types.ErrRateLimited
+	}
+
+	var response RedmineErrorResponse
+	if err := json.Unmarshal(body, &response); err == nil && len(response.Errors) > 0 {
+		log.Println("Request not successful:", response.Errors)
+		return &types.ValidationError{Errors: response.Errors}
+	}
+
+	return &StatusError{StatusCode: status}
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, types.ErrRateLimited) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	var validationErr *types.ValidationError
+	if errors.As(err, &validationErr) {
+		return false
+	}
 
+	// Network errors
+	return !errors.Is(err, types.ErrUnauthorized) &&
+		!errors.Is(err, types.ErrForbidden) &&
+		!errors.Is(err, types.ErrNotFound)
+}
+
+func RequestGET[T any](ctx context.Context, client *Client, token, url string) (*T, error) {
+	body, err := client.request(ctx, http.MethodGet, token, url, nil)
 	if err != nil {
-		log.Println("Error creating request:", err)
 		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	var result T
+	err = json.Unmarshal(body, &result)
 	if err != nil {
-		log.Println("Error fetching issue:", err)
+		log.Println("Error unmarshalling response:", err)
 		return nil, err
 	}
 
-	defer resp.Body.Close()
+	return &result, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// RequestPOST sends the body and decodes the created resource into T.
+func RequestPOST[T any](ctx context.Context, client *Client, token, url string, rqBody []byte) (*T, error) {
+	body, err := client.request(ctx, http.MethodPost, token, url, rqBody)
 	if err != nil {
-		log.Println("Error reading response body:", err)
 		return nil, err
 	}
 
-	switch resp.StatusCode {
-	case 201:
-		return nil, nil
-	case 401:
-		return nil, fmt.Errorf("Unauthorized")
-	}
-
 	var result T
 	err = json.Unmarshal(body, &result)
 	if err != nil {
@@ -78,3 +203,13 @@ func RequestPOST[T any](token, url string, rqBody io.Reader) (*T, error) {
 
 	return &result, nil
 }
+
+func RequestPUT(ctx context.Context, client *Client, token, url string, rqBody []byte) error {
+	_, err := client.request(ctx, http.MethodPut, token, url, rqBody)
+	return err
+}
+
+func RequestDELETE(ctx context.Context, client *Client, token, url string) error {
+	_, err := client.request(ctx, http.MethodDelete, token, url, nil)
+	return err
+}
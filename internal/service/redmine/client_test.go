@@ -0,0 +1,72 @@
+package redmine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestRequestGETRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"issue": {"id": 1, "subject": "Subject"}}`))
+	}))
+	defer server.Close()
+
+	response, err := RequestGET[RedmineIssueResponse](context.Background(), NewClient(1, 2), "token", server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if response.Issue.Subject != "Subject" || calls != 3 {
+		t.Errorf("Expected subject after 3 calls, got %v after %v calls", response.Issue.Subject, calls)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		body     string
+		expected error
+		calls    int
+	}{
+		{http.StatusNotFound, "", types.ErrNotFound, 1},
+		{http.StatusForbidden, "", types.ErrForbidden, 1},
+		{http.StatusUnauthorized, "", types.ErrUnauthorized, 1},
+		{http.StatusTooManyRequests, "", types.ErrRateLimited, 2},
+		{http.StatusUnprocessableEntity, `{"errors": ["Hours is invalid"]}`, &types.ValidationError{}, 1},
+	}
+
+	for _, test := range tests {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+
+		_, err := RequestGET[RedmineIssueResponse](context.Background(), NewClient(1, 1), "token", server.URL)
+		server.Close()
+
+		var validationErr *types.ValidationError
+		if _, ok := test.expected.(*types.ValidationError); ok {
+			if !errors.As(err, &validationErr) || validationErr.Error() != "Hours is invalid" {
+				t.Errorf("Expected validation error for status %v, got %v", test.status, err)
+			}
+		} else if !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for status %v, got %v", test.expected, test.status, err)
+		}
+
+		if calls != test.calls {
+			t.Errorf("Expected %v calls for status %v, got %v", test.calls, test.status, calls)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package redmine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// LoadCustomFields returns the time entry custom fields of the instance a task
+// goes to, with the defaults configured for its project.
+func (r *Redmine) LoadCustomFields(ctx context.Context, task types.TasksToSync) ([]types.CustomField, error) {
+	instance, _, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := instance.getCustomFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for project, defaults := range instance.ProjectCustomFields {
+		if !strings.EqualFold(project, task.Project) {
+			continue
+		}
+		for i, field := range fields {
+			for name, value := range defaults {
+				if strings.EqualFold(name, field.Name) || name == strconv.Itoa(field.Id) {
+					fields[i].Value = value
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// getCustomFields returns the fields declared in the config or, when there are
+// none, the ones defined in Redmine. Redmine only lists them to
+// administrators, so no fields are returned when the request is forbidden.
+func (i *RedmineInstance) getCustomFields(ctx context.Context) ([]types.CustomField, error) {
+	if len(i.CustomFields) > 0 {
+		return append([]types.CustomField{}, i.CustomFields...), nil
+	}
+
+	key := fmt.Sprintf("redmine:%s:custom_fields", i.Url)
+
+	fields, err := cached(ctx, i.cache, key, func(ctx context.Context) (*[]types.CustomField, error) {
+		url := fmt.Sprintf("%s/custom_fields.json", i.Url)
+
+		response, err := RequestGET[RedmineCustomFieldsResponse](ctx, i.client, i.Token, url)
+		if errors.Is(err, types.ErrForbidden) {
+			log.Println("Custom fields can not be listed, declare them in the config")
+			return &[]types.CustomField{}, nil
+		}
+		if err != nil {
+			log.Println("Error fetching custom fields:", err)
+			return nil, err
+		}
+
+		fields := []types.CustomField{}
+		for _, field := range response.CustomFields {
+			if field.CustomizedType == "time_entry" {
+				fields = append(fields, newCustomField(field))
+			}
+		}
+		return &fields, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return *fields, nil
+}
+
+func newCustomField(field RedmineCustomField) types.CustomField {
+	customField := types.CustomField{
+		Id:       field.Id,
+		Name:     field.Name,
+		Required: field.IsRequired,
+		Value:    field.DefaultValue,
+	}
+
+	for _, value := range field.PossibleValues {
+		customField.PossibleValues = append(customField.PossibleValues, value.Value)
+	}
+	if field.FieldFormat == "bool" && len(customField.PossibleValues) == 0 {
+		customField.PossibleValues = []string{"0", "1"}
+	}
+
+	return customField
+}
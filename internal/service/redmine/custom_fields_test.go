@@ -0,0 +1,57 @@
+package redmine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestLoadCustomFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"custom_fields": [
+			{"id": 1, "name": "Billable", "customized_type": "time_entry", "field_format": "bool", "is_required": true, "default_value": "0"},
+			{"id": 2, "name": "Phase", "customized_type": "time_entry", "field_format": "list", "possible_values": [{"value": "Dev"}, {"value": "QA"}]},
+			{"id": 3, "name": "Customer", "customized_type": "issue", "field_format": "string"}
+		]}`))
+	}))
+	defer server.Close()
+
+	config := `{"url": "` + server.URL + `", "project_custom_fields": {"Portal": {"billable": "1", "2": "QA"}}}`
+	redmine, err := NewRedmine([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, err := redmine.LoadCustomFields(context.Background(), types.TasksToSync{ExternalId: "1", Project: "portal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fields) != 2 {
+		t.Fatalf("Expected 2 time entry fields, got %+v", fields)
+	}
+	if fields[0].Value != "1" || !fields[0].Required || len(fields[0].PossibleValues) != 2 {
+		t.Errorf("Unexpected Billable field %+v", fields[0])
+	}
+	if fields[1].Value != "QA" || fields[1].Required {
+		t.Errorf("Unexpected Phase field %+v", fields[1])
+	}
+
+	fields, _ = redmine.LoadCustomFields(context.Background(), types.TasksToSync{ExternalId: "1", Project: "Other"})
+	if fields[0].Value != "0" || fields[1].Value != "" {
+		t.Errorf("Expected Redmine defaults for other projects, got %+v", fields)
+	}
+
+	preview, _ := redmine.PreviewTask(types.TasksToSync{
+		ExternalId:   "1",
+		Duration:     3600,
+		CustomFields: []types.CustomFieldValue{{Id: 1, Value: "1"}},
+	}, 9)
+	expected := `{"time_entry":{"issue_id":"1","hours":"1h","comments":"","spent_on":"","activity_id":9,"custom_fields":[{"id":1,"value":"1"}]}}`
+	if preview.Body != expected {
+		t.Errorf("Expected body %v, got %v", expected, preview.Body)
+	}
+}
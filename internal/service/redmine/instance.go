@@ -0,0 +1,116 @@
+package redmine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+type RedmineInstance struct {
+	Name            string        `json:"name"`
+	DefaultActivity IntFromString `json:"default_activity"`
+	Token           string        `json:"token"`
+	Url             string        `json:"url"`
+	Projects        []string      `json:"projects"`
+	Timeout         int           `json:"timeout"`
+	Retries         *int          `json:"retries"`
+	NotesInComments bool          `json:"notes_in_comments"`
+
+	CustomFields        []types.CustomField          `json:"custom_fields"`
+	ProjectCustomFields map[string]map[string]string `json:"project_custom_fields"`
+
+	client *Client
+	cache  *cache
+}
+
+// setupClient creates the HTTP client of the instance. Timeout and retries not
+// set fall back to the ones of the parent (the top level instance) and then to
+// the defaults.
+func (i *RedmineInstance) setupClient(parent *RedmineInstance) {
+	timeout := i.Timeout
+	retries := i.Retries
+	if parent != nil {
+		if timeout == 0 {
+			timeout = parent.Timeout
+		}
+		if retries == nil {
+			retries = parent.Retries
+		}
+	}
+
+	defaultRetries := DEFAULT_RETRIES
+	if retries == nil {
+		retries = &defaultRetries
+	}
+
+	i.client = NewClient(timeout, *retries)
+}
+
+// instance picks the Redmine server a task belongs to, and returns it together
+// with the issue id without the instance prefix. An "acme:1234" external id
+// goes to the "acme" instance; otherwise the project mapping is used, falling
+// back to the top level (default) instance.
+func (r *Redmine) instance(externalId, project string) (*RedmineInstance, string, error) {
+	if name, issueId, found := strings.Cut(externalId, ":"); found {
+		for i := range r.Instances {
+			if r.Instances[i].Name == name {
+				return &r.Instances[i], issueId, nil
+			}
+		}
+		return nil, "", fmt.Errorf("unknown Redmine instance: %s", name)
+	}
+
+	if project != "" {
+		for i := range r.Instances {
+			if slices.ContainsFunc(r.Instances[i].Projects, func(p string) bool { return strings.EqualFold(p, project) }) {
+				return &r.Instances[i], externalId, nil
+			}
+		}
+	}
+
+	if r.Url != "" {
+		return &r.RedmineInstance, externalId, nil
+	}
+
+	if len(r.Instances) == 1 {
+		return &r.Instances[0], externalId, nil
+	}
+
+	return nil, "", fmt.Errorf("no Redmine instance for issue %s", externalId)
+}
+
+// LocateIssue returns the name of the instance an external id goes to, empty
+// for the top level one, and the issue id there.
+func (r *Redmine) LocateIssue(externalId, project string) (string, string, error) {
+	instance, issueId, err := r.instance(externalId, project)
+	if err != nil {
+		return "", "", err
+	}
+	if instance == &r.RedmineInstance {
+		return "", issueId, nil
+	}
+	return instance.Name, issueId, nil
+}
+
+// namedInstance returns the instance with the given name, or the default one
+// when the name is empty.
+func (r *Redmine) namedInstance(name string) (*RedmineInstance, error) {
+	if name == "" || name == r.Name {
+		if r.Url != "" {
+			return &r.RedmineInstance, nil
+		}
+		if name == "" && len(r.Instances) > 0 {
+			return &r.Instances[0], nil
+		}
+	}
+
+	for i := range r.Instances {
+		if r.Instances[i].Name == name {
+			return &r.Instances[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown Redmine instance: %s", name)
+}
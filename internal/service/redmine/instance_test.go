@@ -0,0 +1,133 @@
+package redmine
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	MAIN_URL = "https://redmine.example.com"
+	ACME_URL = "https://redmine.acme.com"
+	BETA_URL = "https://redmine.beta.com"
+)
+
+func newTestRedmine(t *testing.T, config string) *Redmine {
+	t.Helper()
+	redmine, err := NewRedmine([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return redmine
+}
+
+func TestInstance(t *testing.T) {
+	instances := `"instances": [
+		{"name": "acme", "url": "` + ACME_URL + `", "projects": ["Acme", "Acme Web"]},
+		{"name": "beta", "url": "` + BETA_URL + `"}
+	]`
+	withTopLevel := newTestRedmine(t, `{"name": "main", "url": "`+MAIN_URL+`", `+instances+`}`)
+	withoutTopLevel := newTestRedmine(t, `{`+instances+`}`)
+	single := newTestRedmine(t, `{"instances": [{"name": "acme", "url": "`+ACME_URL+`"}]}`)
+
+	tests := []struct {
+		name       string
+		redmine    *Redmine
+		externalId string
+		project    string
+		url        string
+		issueId    string
+		err        string
+	}{
+		{"prefix", withTopLevel, "acme:12", "", ACME_URL, "12", ""},
+		{"prefix before the project", withTopLevel, "beta:12", "Acme", BETA_URL, "12", ""},
+		{"unknown prefix", withTopLevel, "gamma:12", "", "", "", "unknown Redmine instance: gamma"},
+		{"project mapping", withTopLevel, "12", "acme web", ACME_URL, "12", ""},
+		{"project not mapped", withTopLevel, "12", "Other", MAIN_URL, "12", ""},
+		{"top level", withTopLevel, "12", "", MAIN_URL, "12", ""},
+		{"project mapping without top level", withoutTopLevel, "12", "Acme", ACME_URL, "12", ""},
+		{"no instance", withoutTopLevel, "12", "Other", "", "", "no Redmine instance for issue 12"},
+		{"single instance", single, "12", "", ACME_URL, "12", ""},
+		{"single instance prefix", single, "acme:12", "", ACME_URL, "12", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instance, issueId, err := test.redmine.instance(test.externalId, test.project)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("expected %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if instance.Url != test.url || issueId != test.issueId {
+				t.Errorf("expected %s issue %s, got %s issue %s", test.url, test.issueId, instance.Url, issueId)
+			}
+		})
+	}
+}
+
+func TestNamedInstance(t *testing.T) {
+	withTopLevel := newTestRedmine(t, `{"name": "main", "url": "`+MAIN_URL+`", "instances": [{"name": "acme", "url": "`+ACME_URL+`"}]}`)
+	withoutTopLevel := newTestRedmine(t, `{"instances": [{"name": "acme", "url": "`+ACME_URL+`"}, {"name": "beta", "url": "`+BETA_URL+`"}]}`)
+
+	tests := []struct {
+		name     string
+		redmine  *Redmine
+		instance string
+		url      string
+	}{
+		{"default", withTopLevel, "", MAIN_URL},
+		{"top level by name", withTopLevel, "main", MAIN_URL},
+		{"named", withTopLevel, "acme", ACME_URL},
+		{"unknown", withTopLevel, "gamma", ""},
+		{"first without top level", withoutTopLevel, "", ACME_URL},
+		{"named without top level", withoutTopLevel, "beta", BETA_URL},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instance, err := test.redmine.namedInstance(test.instance)
+			if test.url == "" {
+				if err == nil {
+					t.Errorf("expected an unknown instance, got %s", instance.Url)
+				}
+				return
+			}
+			if err != nil || instance.Url != test.url {
+				t.Errorf("expected %s, got %+v %v", test.url, instance, err)
+			}
+		})
+	}
+}
+
+func TestLocateIssue(t *testing.T) {
+	redmine := newTestRedmine(t, `{"url": "`+MAIN_URL+`", "instances": [{"name": "acme", "url": "`+ACME_URL+`", "projects": ["Acme"]}]}`)
+	single := newTestRedmine(t, `{"instances": [{"name": "acme", "url": "`+ACME_URL+`"}]}`)
+
+	tests := []struct {
+		redmine    *Redmine
+		externalId string
+		project    string
+		instance   string
+		issueId    string
+	}{
+		{redmine, "12", "", "", "12"},
+		{redmine, "12", "Acme", "acme", "12"},
+		{redmine, "acme:12", "", "acme", "12"},
+		{single, "12", "", "acme", "12"},
+	}
+
+	for _, test := range tests {
+		instance, issueId, err := test.redmine.LocateIssue(test.externalId, test.project)
+		if err != nil || instance != test.instance || issueId != test.issueId {
+			t.Errorf("%s in %q: expected %s:%s, got %s:%s %v", test.externalId, test.project, test.instance, test.issueId, instance, issueId, err)
+		}
+	}
+
+	if _, _, err := redmine.LocateIssue("gamma:12", ""); err == nil {
+		t.Error("expected an unknown instance to fail")
+	}
+}
@@ -0,0 +1,67 @@
+package redmine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// LoadIssueStatuses returns the statuses the issue of a task can be moved to,
+// its current status and its done ratio. Redmine versions without
+// allowed_statuses get every status instead.
+func (r *Redmine) LoadIssueStatuses(ctx context.Context, task types.TasksToSync) ([]types.IssueStatus, *types.IssueStatus, int, error) {
+	instance, issueId, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	url := fmt.Sprintf("%s/issues/%s.json?include=allowed_statuses", instance.Url, issueId)
+	response, err := RequestGET[RedmineIssueDetailResponse](ctx, instance.client, instance.Token, url)
+	if err != nil {
+		log.Println("Error fetching issue statuses:", err)
+		return nil, nil, 0, err
+	}
+
+	issue := response.Issue
+	statuses := issue.AllowedStatuses
+	if len(statuses) == 0 {
+		url := fmt.Sprintf("%s/issue_statuses.json", instance.Url)
+		response, err := RequestGET[RedmineIssueStatusesResponse](ctx, instance.client, instance.Token, url)
+		if err != nil {
+			log.Println("Error fetching issue statuses:", err)
+			return nil, nil, 0, err
+		}
+		statuses = response.IssueStatuses
+	}
+
+	return statuses, &issue.Status, issue.DoneRatio, nil
+}
+
+func (r *Redmine) UpdateIssue(ctx context.Context, task types.TasksToSync, update types.IssueUpdate) error {
+	instance, issueId, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/issues/%s.json", instance.Url, issueId)
+
+	body, err := json.Marshal(RedmineIssueUpdateRequest{
+		Issue: RedmineIssueUpdate{
+			StatusId:  update.StatusId,
+			DoneRatio: update.DoneRatio,
+			Notes:     update.Notes,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := RequestPUT(ctx, instance.client, instance.Token, url, body); err != nil {
+		log.Println("Error updating issue:", err)
+		return fmt.Errorf("Error updating issue: %w", err)
+	}
+	return nil
+}
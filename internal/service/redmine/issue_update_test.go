@@ -0,0 +1,53 @@
+package redmine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestUpdateIssue(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			gotMethod, gotPath, gotBody = r.Method, r.URL.Path, string(body)
+		case r.URL.Path == "/issue_statuses.json":
+			w.Write([]byte(`{"issue_statuses": [{"id": 1, "name": "New"}, {"id": 2, "name": "In Progress"}]}`))
+		default:
+			w.Write([]byte(`{"issue": {"id": 42, "status": {"id": 1, "name": "New"}, "done_ratio": 20}}`))
+		}
+	}))
+	defer server.Close()
+
+	redmine, err := NewRedmine([]byte(`{"url": "` + server.URL + `"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := types.TasksToSync{ExternalId: "42"}
+
+	statuses, current, doneRatio, err := redmine.LoadIssueStatuses(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || current.Name != "New" || doneRatio != 20 {
+		t.Errorf("Expected all statuses when allowed_statuses is missing, got %v %v %v", statuses, current, doneRatio)
+	}
+
+	ratio := 50
+	if err := redmine.UpdateIssue(context.Background(), task, types.IssueUpdate{StatusId: 2, DoneRatio: &ratio}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"issue":{"status_id":2,"done_ratio":50}}`
+	if gotMethod != http.MethodPut || gotPath != "/issues/42.json" || gotBody != expected {
+		t.Errorf("Expected PUT /issues/42.json %v, got %v %v %v", expected, gotMethod, gotPath, gotBody)
+	}
+}
@@ -0,0 +1,73 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+var issueStatusFilters = map[string]string{
+	"":       "open",
+	"open":   "open",
+	"closed": "closed",
+	"all":    "*",
+}
+
+// ListMyIssues returns a page of the issues assigned to the API key owner, and
+// the total number of issues matching the filter.
+func (r *Redmine) ListMyIssues(ctx context.Context, filter types.IssueFilter) ([]types.Issue, int, error) {
+	instance, err := r.namedInstance(filter.Instance)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	status, ok := issueStatusFilters[filter.Status]
+	if !ok {
+		status = filter.Status
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = types.ISSUES_PAGE_SIZE
+	}
+
+	query := url.Values{}
+	query.Set("assigned_to_id", "me")
+	query.Set("status_id", status)
+	query.Set("sort", "updated_on:desc")
+	query.Set("offset", strconv.Itoa(filter.Offset))
+	query.Set("limit", strconv.Itoa(limit))
+	if filter.Project != "" {
+		query.Set("project_id", filter.Project)
+	}
+	if filter.Text != "" {
+		query.Set("subject", "~"+filter.Text)
+	}
+
+	response, err := RequestGET[RedmineIssuesResponse](ctx, instance.client, instance.Token, fmt.Sprintf("%s/issues.json?%s", instance.Url, query.Encode()))
+	if err != nil {
+		log.Println("Error fetching issues:", err)
+		return nil, 0, err
+	}
+
+	issues := make([]types.Issue, len(response.Issues))
+	for i, issue := range response.Issues {
+		externalId := strconv.Itoa(issue.Id)
+		if instance != &r.RedmineInstance {
+			externalId = instance.Name + ":" + externalId
+		}
+
+		issues[i] = types.Issue{
+			ExternalId: externalId,
+			Subject:    issue.Subject,
+			Project:    issue.Project.Name,
+			Status:     issue.Status.Name,
+		}
+	}
+
+	return issues, response.TotalCount, nil
+}
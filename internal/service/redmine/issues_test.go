@@ -0,0 +1,108 @@
+package redmine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestListMyIssues(t *testing.T) {
+	var query url.Values
+	var token string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/issues.json" {
+			http.NotFound(w, r)
+			return
+		}
+		query, token = r.URL.Query(), r.Header.Get("X-Redmine-API-Key")
+		w.Write([]byte(`{"issues": [
+			{"id": 12, "subject": "Fix the login", "project": {"id": 1, "name": "mytime"}, "status": {"id": 2, "name": "In Progress"}},
+			{"id": 7, "subject": "Export to CSV", "project": {"id": 1, "name": "mytime"}, "status": {"id": 1, "name": "New"}}
+		], "total_count": 40}`))
+	})
+	main := httptest.NewServer(handler)
+	defer main.Close()
+	acme := httptest.NewServer(handler)
+	defer acme.Close()
+
+	redmine, err := NewRedmine([]byte(`{"url": "` + main.URL + `", "token": "main-key", "instances": [
+		{"name": "acme", "url": "` + acme.URL + `", "token": "acme-key"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter types.IssueFilter
+		token  string
+		query  url.Values
+		ids    []string
+	}{
+		{
+			"top level",
+			types.IssueFilter{Status: "closed", Project: "mytime", Text: "login", Offset: 25},
+			"main-key",
+			url.Values{
+				"assigned_to_id": {"me"},
+				"status_id":      {"closed"},
+				"sort":           {"updated_on:desc"},
+				"offset":         {"25"},
+				"limit":          {"25"},
+				"project_id":     {"mytime"},
+				"subject":        {"~login"},
+			},
+			[]string{"12", "7"},
+		},
+		{
+			"named instance",
+			types.IssueFilter{Instance: "acme", Status: "all", Limit: 10},
+			"acme-key",
+			url.Values{
+				"assigned_to_id": {"me"},
+				"status_id":      {"*"},
+				"sort":           {"updated_on:desc"},
+				"offset":         {"0"},
+				"limit":          {"10"},
+			},
+			[]string{"acme:12", "acme:7"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues, total, err := redmine.ListMyIssues(context.Background(), test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if token != test.token {
+				t.Errorf("expected the key %s, got %s", test.token, token)
+			}
+			if query.Encode() != test.query.Encode() {
+				t.Errorf("expected the query %s, got %s", test.query.Encode(), query.Encode())
+			}
+
+			if total != 40 || len(issues) != len(test.ids) {
+				t.Fatalf("expected %d of 40 issues, got %d of %d", len(test.ids), len(issues), total)
+			}
+			for i, id := range test.ids {
+				if issues[i].ExternalId != id {
+					t.Errorf("expected the issue %s, got %+v", id, issues[i])
+				}
+			}
+			if issues[0].Subject != "Fix the login" || issues[0].Project != "mytime" || issues[0].Status != "In Progress" {
+				t.Errorf("unexpected issue %+v", issues[0])
+			}
+		})
+	}
+
+	if _, _, err := redmine.ListMyIssues(context.Background(), types.IssueFilter{Instance: "gamma"}); err == nil {
+		t.Error("expected an unknown instance to fail")
+	}
+}
@@ -1,114 +1,284 @@
 package redmine
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strconv"
 
-	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
 	"github.com/francescarpi/mytime/internal/util"
 )
 
+// Redmine keeps the legacy single server config at the top level, which is
+// what the Rust version reads, and any additional named servers in Instances.
 type Redmine struct {
-	DefaultActivity IntFromString `json:"default_activity"`
-	Token           string        `json:"token"`
-	Url             string        `json:"url"`
+	RedmineInstance
+	Instances []RedmineInstance `json:"instances"`
+	CacheTTL  int               `json:"cache_ttl"`
 }
 
-func NewRedmine(service *service.Service) *Redmine {
-	settings, err := service.Repo.GetSettings()
-	if err != nil {
-		panic(err)
+func NewRedmine(config []byte) (*Redmine, error) {
+	var redmine Redmine
+	if err := json.Unmarshal(config, &redmine); err != nil {
+		return nil, err
 	}
 
-	var redmine Redmine
-	err = json.Unmarshal([]byte(settings.IntegrationConfig), &redmine)
-	if err != nil {
-		panic(err)
+	redmine.setupClient(nil)
+	redmine.cache = newCache(redmine.CacheTTL)
+	for i := range redmine.Instances {
+		redmine.Instances[i].setupClient(&redmine.RedmineInstance)
+		redmine.Instances[i].cache = redmine.cache
 	}
 
-	return &redmine
+	return &redmine, nil
 }
 
-func (r *Redmine) GetIssue(externalId string) (*RedmineIssue, error) {
-	url := fmt.Sprintf("%s/issues/%s.json", r.Url, externalId)
+func (r *Redmine) SetCache(store types.Cache) {
+	r.cache.store = store
+}
 
-	response, err := RequestGET[RedmineIssueResponse](r.Token, url)
+func (r *Redmine) Refresh() {
+	r.cache.refresh()
+}
+
+func (r *Redmine) GetIssue(ctx context.Context, externalId, project string) (*RedmineIssue, error) {
+	instance, issueId, err := r.instance(externalId, project)
 	if err != nil {
-		log.Println("Error fetching issue:", err)
 		return nil, err
 	}
-	return &response.Issue, nil
+	return instance.getIssue(ctx, issueId)
+}
+
+// ValidateId checks the issue of the external id. When Redmine fails for any
+// reason other than the issue not existing, the last copy of the issue in the
+// cache is returned, flagged as cached.
+func (r *Redmine) ValidateId(ctx context.Context, externalId, project string) (*types.Issue, error) {
+	instance, issueId, err := r.instance(externalId, project)
+	if err != nil {
+		return nil, err
+	}
+
+	offline := false
+	issue, err := instance.getIssue(ctx, issueId)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) || errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		if issue = stale[RedmineIssue](instance.cache, instance.issueKey(issueId)); issue == nil {
+			return nil, err
+		}
+		offline = true
+	}
+
+	if issue.Id == 0 {
+		return nil, types.ErrNotFound
+	}
+
+	return &types.Issue{
+		ExternalId: externalId,
+		Subject:    issue.Subject,
+		Project:    issue.Project.Name,
+		Status:     issue.Status.Name,
+		Cached:     offline,
+	}, nil
 }
 
-func (r *Redmine) LoadActivities(externalId string) (*[]RedmineProjectActivity, *RedmineProjectActivity, error) {
-	issue, err := r.GetIssue(externalId)
+func (i *RedmineInstance) issueKey(issueId string) string {
+	return fmt.Sprintf("redmine:%s:issue:%s", i.Url, issueId)
+}
+
+func (i *RedmineInstance) getIssue(ctx context.Context, issueId string) (*RedmineIssue, error) {
+	return cached(ctx, i.cache, i.issueKey(issueId), func(ctx context.Context) (*RedmineIssue, error) {
+		url := fmt.Sprintf("%s/issues/%s.json", i.Url, issueId)
+
+		response, err := RequestGET[RedmineIssueResponse](ctx, i.client, i.Token, url)
+		if err != nil {
+			log.Println("Error fetching issue:", err)
+			return nil, err
+		}
+		return &response.Issue, nil
+	})
+}
+
+func (i *RedmineInstance) getProjectActivities(ctx context.Context, projectId int) (*[]types.Activity, error) {
+	key := fmt.Sprintf("redmine:%s:activities:%d", i.Url, projectId)
+
+	return cached(ctx, i.cache, key, func(ctx context.Context) (*[]types.Activity, error) {
+		url := fmt.Sprintf("%s/projects/%d.json?include=time_entry_activities", i.Url, projectId)
+
+		response, err := RequestGET[RedmineProjectResponse](ctx, i.client, i.Token, url)
+		if err != nil {
+			log.Println("Error fetching project:", err)
+			return nil, err
+		}
+		return &response.Project.TimeEntryActivities, nil
+	})
+}
+
+func (r *Redmine) LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error) {
+	instance, issueId, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issue, err := instance.getIssue(ctx, issueId)
 	if err != nil {
 		log.Println("Error getting issue:", err)
 		return nil, nil, err
 	}
 
-	url := fmt.Sprintf("%s/projects/%d.json?include=time_entry_activities", r.Url, issue.Project.Id)
-	response, err := RequestGET[RedmineProjectResponse](r.Token, url)
+	activities, err := instance.getProjectActivities(ctx, issue.Project.Id)
 	if err != nil {
-		log.Println("Error fetching project:", err)
 		return nil, nil, err
 	}
 
-	var defaultActivity RedmineProjectActivity
-	activities := response.Project.TimeEntryActivities
-	for _, activity := range activities {
-		if activity.Id == int(r.DefaultActivity) {
+	var defaultActivity types.Activity
+	for _, activity := range *activities {
+		if activity.Id == int(instance.DefaultActivity) {
 			defaultActivity = activity
 		}
 	}
 
-	return &activities, &defaultActivity, nil
+	return activities, &defaultActivity, nil
 }
 
-func (r *Redmine) SendTask(externalId, desc, date string, duration int, activityId int) error {
-	url := fmt.Sprintf("%s/time_entries.json", r.Url)
+// COMMENTS_LENGTH is the maximum length of the comments of a time entry.
+const COMMENTS_LENGTH = 1024
 
-	type TimeEntry struct {
-		IssueId    string `json:"issue_id"`
-		Hours      string `json:"hours"`
-		Comments   string `json:"comments"`
-		SpentOn    string `json:"spent_on"`
-		ActivityId int    `json:"activity_id"`
-	}
+type TimeEntry struct {
+	IssueId      string                   `json:"issue_id,omitempty"`
+	Hours        string                   `json:"hours"`
+	Comments     string                   `json:"comments"`
+	SpentOn      string                   `json:"spent_on"`
+	ActivityId   int                      `json:"activity_id,omitempty"`
+	CustomFields []types.CustomFieldValue `json:"custom_fields,omitempty"`
+}
+
+type TimeEntryRequest struct {
+	TimeEntry TimeEntry `json:"time_entry"`
+}
 
-	timeEntry := struct {
-		TimeEntry TimeEntry `json:"time_entry"`
-	}{
+func newTimeEntryRequest(issueId string, task types.TasksToSync, activityId int, comments string) TimeEntryRequest {
+	return TimeEntryRequest{
 		TimeEntry: TimeEntry{
-			IssueId:    externalId,
-			Hours:      util.HumanizeDuration(duration),
-			Comments:   desc,
-			SpentOn:    date,
-			ActivityId: activityId,
+			IssueId:      issueId,
+			Hours:        util.HumanizeDuration(task.Duration),
+			Comments:     comments,
+			SpentOn:      task.Date,
+			ActivityId:   activityId,
+			CustomFields: task.CustomFields,
 		},
 	}
+}
 
-	body, err := json.Marshal(timeEntry)
+// comments returns the description of a task group, followed by its notes
+// when the instance is set to send them, cut to the length Redmine accepts.
+func (r *Redmine) comments(instance *RedmineInstance, task types.TasksToSync) string {
+	comments := task.Desc
+	if (instance.NotesInComments || r.NotesInComments) && task.Notes != "" {
+		comments += "\n\n" + task.Notes
+	}
+
+	if runes := []rune(comments); len(runes) > COMMENTS_LENGTH {
+		comments = string(runes[:COMMENTS_LENGTH-1]) + "…"
+	}
+	return comments
+}
+
+// timeEntryRequest builds the request that creates the time entry of a task
+// group, returning the instance it goes to, the url and the body.
+func (r *Redmine) timeEntryRequest(task types.TasksToSync, activityId int) (*RedmineInstance, string, []byte, error) {
+	instance, issueId, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	url := fmt.Sprintf("%s/time_entries.json", instance.Url)
+
+	body, err := json.Marshal(newTimeEntryRequest(issueId, task, activityId, r.comments(instance, task)))
+	if err != nil {
+		log.Println("Error marshalling time entry:", err)
+		return nil, "", nil, err
+	}
+
+	return instance, url, body, nil
+}
+
+func (r *Redmine) PreviewTask(task types.TasksToSync, activityId int) (*types.RequestPreview, error) {
+	_, url, body, err := r.timeEntryRequest(task, activityId)
+	if err != nil {
+		return nil, err
+	}
+	return &types.RequestPreview{Method: http.MethodPost, Url: url, Body: string(body)}, nil
+}
+
+func (r *Redmine) SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error) {
+	instance, url, body, err := r.timeEntryRequest(task, activityId)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := RequestPOST[RedmineTimeEntryResponse](ctx, instance.client, instance.Token, url, body)
+	if err != nil {
+		log.Println("Error sending task:", err, string(body))
+		return "", fmt.Errorf("Error sending task: %w", err)
+	}
+
+	return strconv.Itoa(response.TimeEntry.Id), nil
+}
+
+// UpdateEntry replaces the hours, comments and date of an already synced time
+// entry with the ones of the task group, and moves it to the issue of the
+// group when its external id has been edited since. An entry can not be moved
+// to another instance.
+func (r *Redmine) UpdateEntry(ctx context.Context, entry model.RemoteEntry, task types.TasksToSync) error {
+	instance, err := r.namedInstance(entry.Instance)
+	if err != nil {
+		return err
+	}
+
+	target, issueId, err := r.instance(task.ExternalId, task.Project)
+	if err != nil {
+		return err
+	}
+	if target != instance {
+		return fmt.Errorf("time entry %s is in %s, delete it there and sync the tasks again", entry.RemoteId, instance.Url)
+	}
+	if issueId == entry.IssueId {
+		issueId = ""
+	}
+
+	url := fmt.Sprintf("%s/time_entries/%s.json", instance.Url, entry.RemoteId)
+
+	body, err := json.Marshal(newTimeEntryRequest(issueId, task, 0, r.comments(instance, task)))
 	if err != nil {
 		log.Println("Error marshalling time entry:", err)
 		return err
 	}
 
-	response, err := RequestPOST[RedmineSendTaskError](r.Token, url, bytes.NewBuffer(body))
-	if response == nil && err == nil {
-		// All good
-		return nil
+	if err := RequestPUT(ctx, instance.client, instance.Token, url, body); err != nil {
+		log.Println("Error updating time entry:", err)
+		return fmt.Errorf("Error updating time entry: %w", err)
 	}
+	return nil
+}
 
-	if response == nil && err != nil {
-		// Generic error
-		log.Println("Error sending task:", err, bytes.NewBuffer(body))
+func (r *Redmine) DeleteEntry(ctx context.Context, entry model.RemoteEntry) error {
+	instance, err := r.namedInstance(entry.Instance)
+	if err != nil {
 		return err
 	}
 
-	log.Println("Task not sent successfully:", response.Errors)
+	url := fmt.Sprintf("%s/time_entries/%s.json", instance.Url, entry.RemoteId)
 
-	return fmt.Errorf("Error sending task: %v", response.Errors)
+	if err := RequestDELETE(ctx, instance.client, instance.Token, url); err != nil {
+		log.Println("Error deleting time entry:", err)
+		return fmt.Errorf("Error deleting time entry: %w", err)
+	}
+	return nil
 }
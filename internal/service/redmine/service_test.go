@@ -0,0 +1,131 @@
+package redmine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestNotesInComments(t *testing.T) {
+	config := `{"url": "https://redmine.example.com", "instances": [
+		{"name": "acme", "url": "https://acme.example.com", "notes_in_comments": true}
+	]}`
+	redmine, err := NewRedmine([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := func(task types.TasksToSync) string {
+		t.Helper()
+		preview, err := redmine.PreviewTask(task, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var request TimeEntryRequest
+		if err := json.Unmarshal([]byte(preview.Body), &request); err != nil {
+			t.Fatal(err)
+		}
+		return request.TimeEntry.Comments
+	}
+
+	task := types.TasksToSync{ExternalId: "1", Desc: "review", Notes: "found a bug"}
+	if got := comments(task); got != "review" {
+		t.Errorf("Expected only the description by default, got %q", got)
+	}
+
+	task.ExternalId = "acme:1"
+	if got := comments(task); got != "review\n\nfound a bug" {
+		t.Errorf("Expected the notes after the description, got %q", got)
+	}
+
+	task.Notes = strings.Repeat("é", 2000)
+	if got := comments(task); utf8.RuneCountInString(got) != COMMENTS_LENGTH || !strings.HasSuffix(got, "…") {
+		t.Errorf("Expected the comments to be cut to %d characters, got %d", COMMENTS_LENGTH, utf8.RuneCountInString(got))
+	}
+}
+
+// recordingServer answers every request with an empty JSON object, keeping
+// its method, path and body.
+func recordingServer(t *testing.T, requests *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAmendEntries(t *testing.T) {
+	var main, acme []string
+	config := `{"url": "` + recordingServer(t, &main).URL + `", "instances": [
+		{"name": "acme", "url": "` + recordingServer(t, &acme).URL + `"}
+	]}`
+	redmine, err := NewRedmine([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := types.TasksToSync{Desc: "review", Duration: 3600, Date: "2025-02-26"}
+	update := func(entry model.RemoteEntry, externalId string) error {
+		task.ExternalId = externalId
+		return redmine.UpdateEntry(context.Background(), entry, task)
+	}
+
+	tests := []struct {
+		name       string
+		entry      model.RemoteEntry
+		externalId string
+		main, acme []string
+	}{
+		{
+			"same issue",
+			model.RemoteEntry{Instance: "acme", IssueId: "1", RemoteId: "42"},
+			"acme:1",
+			nil,
+			[]string{`PUT /time_entries/42.json {"time_entry":{"hours":"1h","comments":"review","spent_on":"2025-02-26"}}`},
+		},
+		{
+			"issue edited",
+			model.RemoteEntry{IssueId: "1", RemoteId: "42"},
+			"2",
+			[]string{`PUT /time_entries/42.json {"time_entry":{"issue_id":"2","hours":"1h","comments":"review","spent_on":"2025-02-26"}}`},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			main, acme = nil, nil
+			if err := update(test.entry, test.externalId); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(main, "\n") != strings.Join(test.main, "\n") || strings.Join(acme, "\n") != strings.Join(test.acme, "\n") {
+				t.Errorf("expected %v and %v, got %v and %v", test.main, test.acme, main, acme)
+			}
+		})
+	}
+
+	// the prefix removed from the external id does not move the entry
+	main, acme = nil, nil
+	entry := model.RemoteEntry{Instance: "acme", IssueId: "1", RemoteId: "42"}
+	if err := update(entry, "1"); err == nil || len(main)+len(acme) != 0 {
+		t.Errorf("expected the move to another instance to fail, got %v %v %v", err, main, acme)
+	}
+
+	// deleted where it was synced
+	if err := redmine.DeleteEntry(context.Background(), entry); err != nil {
+		t.Fatal(err)
+	}
+	if len(main) != 0 || len(acme) != 1 || acme[0] != "DELETE /time_entries/42.json" {
+		t.Errorf("expected the entry deleted in acme, got %v and %v", main, acme)
+	}
+}
@@ -0,0 +1,80 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const TIME_ENTRIES_PAGE_SIZE = 100
+
+// ListMyTimeEntries returns the time entries logged by the API key owner
+// between both dates, in every configured instance.
+func (r *Redmine) ListMyTimeEntries(ctx context.Context, from, to time.Time) ([]types.TimeEntry, error) {
+	var result []types.TimeEntry
+
+	if r.Url != "" {
+		entries, err := r.RedmineInstance.listMyTimeEntries(ctx, from, to, "")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+
+	for _, instance := range r.Instances {
+		entries, err := instance.listMyTimeEntries(ctx, from, to, instance.Name+":")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+	}
+
+	return result, nil
+}
+
+func (i *RedmineInstance) listMyTimeEntries(ctx context.Context, from, to time.Time, prefix string) ([]types.TimeEntry, error) {
+	var result []types.TimeEntry
+
+	for offset := 0; ; offset += TIME_ENTRIES_PAGE_SIZE {
+		query := url.Values{}
+		query.Set("user_id", "me")
+		query.Set("from", from.Format(time.DateOnly))
+		query.Set("to", to.Format(time.DateOnly))
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(TIME_ENTRIES_PAGE_SIZE))
+
+		response, err := RequestGET[RedmineTimeEntriesResponse](ctx, i.client, i.Token, fmt.Sprintf("%s/time_entries.json?%s", i.Url, query.Encode()))
+		if err != nil {
+			log.Println("Error fetching time entries:", err)
+			return nil, err
+		}
+
+		for _, entry := range response.TimeEntries {
+			externalId := ""
+			if entry.Issue.Id != 0 {
+				externalId = prefix + strconv.Itoa(entry.Issue.Id)
+			}
+
+			result = append(result, types.TimeEntry{
+				Id:         strconv.Itoa(entry.Id),
+				ExternalId: externalId,
+				Date:       entry.SpentOn,
+				Duration:   int(math.Round(entry.Hours * 3600)),
+				Comments:   entry.Comments,
+				Activity:   entry.Activity.Name,
+			})
+		}
+
+		if len(response.TimeEntries) == 0 || offset+len(response.TimeEntries) >= response.TotalCount {
+			break
+		}
+	}
+
+	return result, nil
+}
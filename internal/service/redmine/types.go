@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+
+	"github.com/francescarpi/mytime/internal/types"
 )
 
 type RedmineIssueProject struct {
@@ -11,31 +13,59 @@ type RedmineIssueProject struct {
 	Name string `json:"name"`
 }
 
+type RedmineIssueStatus struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
 type RedmineIssue struct {
 	Id      int                 `json:"id"`
+	Subject string              `json:"subject"`
 	Project RedmineIssueProject `json:"project"`
+	Status  RedmineIssueStatus  `json:"status"`
 }
 
 type RedmineIssueResponse struct {
 	Issue RedmineIssue `json:"issue"`
 }
 
-type RedmineProjectActivity struct {
-	Id      int    `json:"id"`
-	Name    string `json:"name"`
-	Default bool
+type RedmineIssuesResponse struct {
+	Issues     []RedmineIssue `json:"issues"`
+	TotalCount int            `json:"total_count"`
 }
 
 type RedmineProject struct {
-	Id                  int                      `json:"id"`
-	TimeEntryActivities []RedmineProjectActivity `json:"time_entry_activities"`
+	Id                  int              `json:"id"`
+	TimeEntryActivities []types.Activity `json:"time_entry_activities"`
 }
 
 type RedmineProjectResponse struct {
 	Project RedmineProject `json:"project"`
 }
 
-type RedmineSendTaskError struct {
+type RedmineTimeEntryIssue struct {
+	Id int `json:"id"`
+}
+
+type RedmineTimeEntry struct {
+	Id       int                   `json:"id"`
+	Issue    RedmineTimeEntryIssue `json:"issue"`
+	Hours    float64               `json:"hours"`
+	Comments string                `json:"comments"`
+	SpentOn  string                `json:"spent_on"`
+	Activity types.Activity        `json:"activity"`
+}
+
+type RedmineTimeEntriesResponse struct {
+	TimeEntries []RedmineTimeEntry `json:"time_entries"`
+	TotalCount  int                `json:"total_count"`
+}
+
+type RedmineTimeEntryResponse struct {
+	TimeEntry RedmineTimeEntry `json:"time_entry"`
+}
+
+type RedmineErrorResponse struct {
 	Errors []string `json:"errors"`
 }
 
@@ -62,3 +92,46 @@ func (i *IntFromString) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid type for IntFromString: %T", v)
 	}
 }
+
+type RedmineCustomFieldValue struct {
+	Value string `json:"value"`
+}
+
+type RedmineCustomField struct {
+	Id             int                       `json:"id"`
+	Name           string                    `json:"name"`
+	CustomizedType string                    `json:"customized_type"`
+	FieldFormat    string                    `json:"field_format"`
+	IsRequired     bool                      `json:"is_required"`
+	DefaultValue   string                    `json:"default_value"`
+	PossibleValues []RedmineCustomFieldValue `json:"possible_values"`
+}
+
+type RedmineCustomFieldsResponse struct {
+	CustomFields []RedmineCustomField `json:"custom_fields"`
+}
+
+type RedmineIssueStatusesResponse struct {
+	IssueStatuses []types.IssueStatus `json:"issue_statuses"`
+}
+
+type RedmineIssueDetail struct {
+	Id              int                 `json:"id"`
+	Status          types.IssueStatus   `json:"status"`
+	DoneRatio       int                 `json:"done_ratio"`
+	AllowedStatuses []types.IssueStatus `json:"allowed_statuses"`
+}
+
+type RedmineIssueDetailResponse struct {
+	Issue RedmineIssueDetail `json:"issue"`
+}
+
+type RedmineIssueUpdate struct {
+	StatusId  int    `json:"status_id,omitempty"`
+	DoneRatio *int   `json:"done_ratio,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+type RedmineIssueUpdateRequest struct {
+	Issue RedmineIssueUpdate `json:"issue"`
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+// SEARCH_LIMIT is the number of tasks a search returns when no limit is given.
+const SEARCH_LIMIT = 500
+
+// SearchDay holds the tasks of a day found by a search, and the sum of their
+// durations.
+type SearchDay struct {
+	Date     string
+	Duration int
+	Tasks    []model.Task
+}
+
+// RebuildSearchIndex creates the search index of the tasks again.
+func (s *Service) RebuildSearchIndex() error {
+	return s.Repo.RebuildSearchIndex()
+}
+
+// SearchTasks finds the tasks matching every word of the query, grouped by
+// day, the most recent first.
+func (s *Service) SearchTasks(query string, dateRange types.DateRange, limit int) ([]SearchDay, error) {
+	if limit <= 0 {
+		limit = SEARCH_LIMIT
+	}
+
+	tasks, err := s.Repo.SearchTasks(query, dateRange, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []SearchDay
+	for _, task := range tasks {
+		date := task.Start.Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, SearchDay{Date: date})
+		}
+		day := &days[len(days)-1]
+		day.Duration += task.Duration
+		day.Tasks = append(day.Tasks, task)
+	}
+	return days, nil
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/francescarpi/mytime/internal/model"
@@ -20,7 +22,16 @@ type WorkedDurationFormatted struct {
 }
 
 type Service struct {
-	Repo repository.Repository
+	Repo    repository.Repository
+	Rounder Rounder
+
+	// undo and redo steps of the changes made through the service
+	undo, redo [][]model.TaskHistory
+}
+
+// Rounder applies the rounding policy of a task group to its duration.
+type Rounder interface {
+	Round(task types.TasksToSync) types.TasksToSync
 }
 
 type SummaryDuration struct {
@@ -89,9 +100,23 @@ func (s *Service) GetSummaryDuration(date time.Time) (SummaryDuration, error) {
 	return result, nil
 }
 
-func (s *Service) CreateTask(description string, project, externalId *string) error {
+// CreateTask starts a new task, closing the open one. The #tags of the
+// description are added to the tags given.
+func (s *Service) CreateTask(description string, project, externalId *string, tags []string) error {
+	return s.track(func() error {
+		return s.createTask(description, project, externalId, taskTags(description, tags))
+	})
+}
+
+func (s *Service) createTask(description string, project, externalId *string, tags []string) error {
+	if project != nil && (externalId == nil || *externalId == "") {
+		if known := s.GetProject(*project); known != nil && known.DefaultExternalId != "" {
+			externalId = &known.DefaultExternalId
+		}
+	}
+
 	s.Repo.CloseOpenedTasks()
-	if err := s.Repo.CreateTask(description, project, externalId); err != nil {
+	if err := s.Repo.CreateTask(description, project, externalId, tags); err != nil {
 		return err
 	}
 	return nil
@@ -103,25 +128,43 @@ func (s *Service) StartStopTask(id uint) error {
 		return err
 	}
 
-	if task.IsOpen() {
-		return s.Repo.CloseTask(id)
+	tags, err := s.Repo.GetTaskTags([]uint{id})
+	if err != nil {
+		return err
 	}
 
-	return s.CreateTask(task.Desc, task.Project, task.ExternalId)
+	return s.track(func() error {
+		if task.IsOpen() {
+			return s.Repo.CloseTask(id)
+		}
+		return s.createTask(task.Desc, task.Project, task.ExternalId, tags[id])
+	})
 }
 
+// UpdateTask saves the changes of a task, adding the #tags of its
+// description to its tags.
 func (s *Service) UpdateTask(task *model.Task) error {
-	if err := s.Repo.UpdateTask(task); err != nil {
+	err := s.track(func() error {
+		return s.Repo.UpdateTask(task)
+	})
+	if err != nil {
 		return err
 	}
+
+	if added := util.DescriptionTags(task.Desc); len(added) > 0 {
+		tags, err := s.Repo.GetTaskTags([]uint{task.ID})
+		if err != nil {
+			return err
+		}
+		return s.Repo.SetTaskTags(task.ID, taskTags(task.Desc, tags[task.ID]))
+	}
 	return nil
 }
 
 func (s *Service) DeleteTask(id uint) error {
-	if err := s.Repo.DeleteTask(id); err != nil {
-		return err
-	}
-	return nil
+	return s.track(func() error {
+		return s.Repo.DeleteTask(id)
+	})
 }
 
 func (s *Service) GetTasksToSync() []types.TasksToSync {
@@ -129,9 +172,100 @@ func (s *Service) GetTasksToSync() []types.TasksToSync {
 	if err != nil {
 		return []types.TasksToSync{}
 	}
-	return tasks
+
+	if s.Rounder != nil {
+		for i := range tasks {
+			tasks[i] = s.Rounder.Round(tasks[i])
+		}
+	}
+	tasks = s.withNotes(tasks)
+
+	entries, err := s.Repo.GetOutboxEntries()
+	if err != nil {
+		return tasks
+	}
+	return withoutOutbox(tasks, entries)
 }
 
 func (s *Service) SetTaskAsReported(id uint) error {
-	return s.Repo.SetTaskAsReported(id)
+	return s.track(func() error {
+		return s.Repo.SetTaskAsReported(id)
+	})
+}
+
+func (s *Service) GetCacheEntry(key string) (*model.CacheEntry, error) {
+	return s.Repo.GetCacheEntry(key)
+}
+
+func (s *Service) SaveCacheEntry(key, value string) error {
+	return s.Repo.SaveCacheEntry(key, value)
+}
+
+// SaveRemoteEntry links the tasks of a group to the time entry they were
+// synced to.
+func (s *Service) SaveRemoteEntry(task types.TasksToSync, entry model.RemoteEntry) error {
+	var taskIds []uint
+	for _, idStr := range task.Ids.IDs {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return err
+		}
+		taskIds = append(taskIds, uint(id))
+	}
+	return s.Repo.SaveRemoteEntry(taskIds, entry)
+}
+
+// GetRemoteEntry returns the remote entry a task was synced to, or nil when it
+// has not been synced or the remote id is unknown.
+func (s *Service) GetRemoteEntry(taskId uint) *model.RemoteEntry {
+	entry, err := s.Repo.GetRemoteEntry(taskId)
+	if err != nil {
+		return nil
+	}
+	return entry
+}
+
+// GetRemoteEntryGroup rebuilds the sync group of a remote entry from the local
+// tasks still linked to it. It returns false when none of them is left.
+func (s *Service) GetRemoteEntryGroup(entry *model.RemoteEntry) (types.TasksToSync, bool, error) {
+	tasks, err := s.Repo.GetRemoteEntryTasks(entry)
+	if err != nil {
+		return types.TasksToSync{}, false, err
+	}
+
+	if len(tasks) == 0 {
+		return types.TasksToSync{}, false, nil
+	}
+
+	first := tasks[0]
+	group := types.TasksToSync{
+		Desc:    first.Desc,
+		Date:    first.Start.Format(time.DateOnly),
+		Project: first.ProjectName(),
+	}
+	if first.ExternalId != nil {
+		group.ExternalId = *first.ExternalId
+	}
+
+	for _, task := range tasks {
+		group.Duration += task.Duration
+		group.Ids.IDs = append(group.Ids.IDs, strconv.Itoa(int(task.ID)))
+	}
+	group.Id = strings.Join(group.Ids.IDs, "-")
+
+	return s.withNotes([]types.TasksToSync{group})[0], true, nil
+}
+
+// MoveRemoteEntry records the instance and issue a remote entry has been
+// moved to.
+func (s *Service) MoveRemoteEntry(entry *model.RemoteEntry, instance, issueId string) error {
+	if err := s.Repo.MoveRemoteEntry(entry, instance, issueId); err != nil {
+		return err
+	}
+	entry.Instance, entry.IssueId = instance, issueId
+	return nil
+}
+
+func (s *Service) DeleteRemoteEntry(entry *model.RemoteEntry) error {
+	return s.Repo.DeleteRemoteEntry(entry)
 }
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/repository"
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func newTestService(t *testing.T) (*Service, *repository.FakeClock) {
+	t.Helper()
+	clock := repository.NewFakeClock(time.Date(2025, 2, 26, 9, 0, 0, 0, time.Local))
+	return &Service{Repo: repository.NewMemoryRepository(clock.Now)}, clock
+}
+
+func TestStartStopTask(t *testing.T) {
+	s, clock := newTestService(t)
+	issue := "ISSUE-1"
+
+	if err := s.CreateTask("review", nil, &issue, nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if err := s.CreateTask("coding", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+
+	// Starting a closed task closes the open one and tracks it again
+	if err := s.StartStopTask(1); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Minute)
+	if err := s.StartStopTask(3); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := s.GetTasksByDate(clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %+v", tasks)
+	}
+	for _, task := range tasks {
+		if task.IsOpen() {
+			t.Errorf("task %d should be closed", task.ID)
+		}
+	}
+	if tasks[0].Desc != "review" || tasks[0].ExternalId == nil || *tasks[0].ExternalId != issue || tasks[0].Duration != 1800 {
+		t.Errorf("expected the reopened review of 1800s, got %+v", tasks[0])
+	}
+}
+
+type fixedRounder struct{}
+
+func (fixedRounder) Round(task types.TasksToSync) types.TasksToSync {
+	task.RawDuration = task.Duration
+	task.Duration = 3600
+	return task
+}
+
+func TestGetTasksToSync(t *testing.T) {
+	s, clock := newTestService(t)
+	s.Rounder = fixedRounder{}
+
+	for _, issue := range []string{"ISSUE-1", "ISSUE-2"} {
+		if err := s.CreateTask("review", nil, &issue, nil); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(20 * time.Minute)
+	}
+	s.Repo.CloseOpenedTasks()
+	if err := s.SaveNotes(1, "checked the logs\n\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := s.GetTasksToSync()
+	if len(tasks) != 2 || tasks[0].Duration != 3600 || tasks[0].RawDuration != 1200 {
+		t.Fatalf("expected 2 rounded groups, got %+v", tasks)
+	}
+	if tasks[0].Notes != "checked the logs" || tasks[1].Notes != "" {
+		t.Errorf("expected the notes of the first group only, got %+v", tasks)
+	}
+
+	if err := s.AddToOutbox(tasks[0], "redmine", 9, errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := s.GetTasksToSync()
+	if len(remaining) != 1 || remaining[0].ExternalId != "ISSUE-2" {
+		t.Fatalf("expected the group in the outbox to be left out, got %+v", remaining)
+	}
+
+	if err := s.MarkAsSynced(tasks[0], model.RemoteEntry{Integration: "redmine", IssueId: "ISSUE-1", RemoteId: "42"}); err != nil {
+		t.Fatal(err)
+	}
+
+	outbox, err := s.GetOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outbox) != 0 {
+		t.Errorf("expected the outbox to be empty, got %+v", outbox)
+	}
+
+	entry := s.GetRemoteEntry(1)
+	if entry == nil || entry.RemoteId != "42" {
+		t.Fatalf("expected task 1 to be linked to 42, got %+v", entry)
+	}
+
+	remaining = s.GetTasksToSync()
+	if !slices.EqualFunc(remaining, []string{"ISSUE-2"}, func(task types.TasksToSync, issue string) bool {
+		return task.ExternalId == issue
+	}) {
+		t.Errorf("expected only ISSUE-2 left to sync, got %+v", remaining)
+	}
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	s, clock := newTestService(t)
+
+	if err := s.CreateTask("deleted by mistake", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if err := s.DeleteTask(1); err != nil {
+		t.Fatal(err)
+	}
+
+	trash, err := s.GetTrash()
+	if err != nil || len(trash) != 1 {
+		t.Fatalf("expected the task in the trash, got %+v %v", trash, err)
+	}
+	if err := s.RestoreFromTrash(trash[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Repo.GetTask(1); err != nil {
+		t.Fatal("expected the task to be restored")
+	}
+
+	// undoing the restore moves it back to the trash
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if trash, _ := s.GetTrash(); len(trash) != 1 {
+		t.Errorf("expected the task back in the trash, got %+v", trash)
+	}
+
+	if purged, err := s.PurgeExpiredTrash(0); err != nil || purged != 0 {
+		t.Errorf("expected nothing purged with 0 days, got %d %v", purged, err)
+	}
+}
+
+func TestProjectDefaults(t *testing.T) {
+	s, _ := newTestService(t)
+	project := "mytime"
+
+	if err := s.CreateTask("coding", &project, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.GetProject(project) != nil {
+		t.Fatal("expected the project not to be created with the task")
+	}
+
+	if err := s.SaveProject(&model.Project{Name: project, DefaultExternalId: "ISSUE-7"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateTask("review", &project, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := s.Repo.GetTask(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ExternalId == nil || *task.ExternalId != "ISSUE-7" {
+		t.Errorf("expected the default external id of the project, got %+v", task.ExternalId)
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	s, clock := newTestService(t)
+
+	for _, desc := range []string{"review", "coding", "review again"} {
+		if err := s.CreateTask(desc, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Hour)
+	}
+	s.Repo.CloseOpenedTasks()
+	clock.Advance(24 * time.Hour)
+	if err := s.CreateTask("review", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Minute)
+
+	days, err := s.SearchTasks("review", types.DateRange{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 || days[0].Date != "2025-02-27" || days[0].Duration != 1800 || len(days[1].Tasks) != 2 || days[1].Duration != 7200 {
+		t.Errorf("expected the reviews grouped by day, got %+v", days)
+	}
+}
+
+func TestRemoteEntryGroup(t *testing.T) {
+	s, clock := newTestService(t)
+	task := createSyncGroup(t, s, clock)[0]
+	if err := s.SaveNotes(1, "checked the logs"); err != nil {
+		t.Fatal(err)
+	}
+
+	remote := model.RemoteEntry{Integration: "redmine", Instance: "acme", IssueId: "1", RemoteId: "42"}
+	if err := s.SaveRemoteEntry(task, remote); err != nil {
+		t.Fatal(err)
+	}
+	entry := s.GetRemoteEntry(3)
+	if entry == nil || entry.Instance != "acme" || entry.IssueId != "1" || entry.RemoteId != "42" {
+		t.Fatalf("expected task 3 linked to the entry, got %+v", entry)
+	}
+	if other := s.GetRemoteEntry(2); other != nil {
+		t.Errorf("expected task 2 not linked, got %+v", other)
+	}
+
+	group, found, err := s.GetRemoteEntryGroup(entry)
+	if err != nil || !found {
+		t.Fatalf("expected the group, got %v %v", found, err)
+	}
+	if group.ExternalId != "ISSUE-1" || group.Duration != 7200 || group.Id != "3-1" || group.Notes != "checked the logs" {
+		t.Errorf("unexpected group %+v", group)
+	}
+
+	// the group follows the tasks deleted and the external id edited
+	edited, _ := s.Repo.GetTask(3)
+	issue := "ISSUE-3"
+	edited.ExternalId = &issue
+	if err := s.UpdateTask(edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteTask(1); err != nil {
+		t.Fatal(err)
+	}
+	group, found, err = s.GetRemoteEntryGroup(entry)
+	if err != nil || !found || group.ExternalId != "ISSUE-3" || group.Duration != 3600 {
+		t.Errorf("expected the edited task left, got %+v %v %v", group, found, err)
+	}
+
+	if err := s.MoveRemoteEntry(entry, "acme", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if entry.IssueId != "3" || s.GetRemoteEntry(3).IssueId != "3" {
+		t.Errorf("expected the entry moved to issue 3, got %+v", s.GetRemoteEntry(3))
+	}
+
+	if err := s.DeleteTask(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, err := s.GetRemoteEntryGroup(entry); found || err != nil {
+		t.Errorf("expected no group once its tasks are deleted, got %v %v", found, err)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/util"
+)
+
+// TagDuration is the time tracked in a day for the tasks with a tag.
+type TagDuration struct {
+	Date     string
+	Tag      string
+	Duration int
+}
+
+func (s *Service) GetTags() ([]model.Tag, error) {
+	return s.Repo.GetTags()
+}
+
+// GetTaskTags returns the tags of the given tasks, by task id.
+func (s *Service) GetTaskTags(tasks []model.Task) (map[uint][]string, error) {
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return s.Repo.GetTaskTags(ids)
+}
+
+// SetTaskTags replaces the tags of a task. The #tags of its description are
+// always kept.
+func (s *Service) SetTaskTags(id uint, tags []string) error {
+	task, err := s.Repo.GetTask(id)
+	if err != nil {
+		return err
+	}
+	return s.Repo.SetTaskTags(id, taskTags(task.Desc, tags))
+}
+
+// GetTagDurations sums the time tracked between both dates by day and tag. A
+// task with more than one tag counts for each of them, and the tasks without
+// tags are left out.
+func (s *Service) GetTagDurations(from, to time.Time) ([]TagDuration, error) {
+	tasks, err := s.Repo.GetTasksBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.GetTaskTags(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := map[TagDuration]int{}
+	for _, task := range tasks {
+		for _, tag := range tags[task.ID] {
+			durations[TagDuration{Date: task.Start.Format(time.DateOnly), Tag: tag}] += task.Duration
+		}
+	}
+
+	result := make([]TagDuration, 0, len(durations))
+	for key, duration := range durations {
+		key.Duration = duration
+		result = append(result, key)
+	}
+	slices.SortFunc(result, func(a, b TagDuration) int {
+		if a.Date != b.Date {
+			return strings.Compare(a.Date, b.Date)
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	return result, nil
+}
+
+// TasksWithTag returns the tasks having the tag, or all of them when the tag
+// is empty.
+func TasksWithTag(tasks []model.Task, tags map[uint][]string, tag string) []model.Task {
+	tag = strings.ToLower(strings.TrimLeft(tag, "#"))
+	if tag == "" {
+		return tasks
+	}
+
+	var result []model.Task
+	for _, task := range tasks {
+		if slices.Contains(tags[task.ID], tag) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// taskTags merges the tags given with the #tags of the description.
+func taskTags(description string, tags []string) []string {
+	return util.ParseTags(strings.Join(append(util.DescriptionTags(description), tags...), ","))
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTagDurations(t *testing.T) {
+	s, clock := newTestService(t)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	must(s.CreateTask("standup #Meeting", nil, nil, []string{"daily"}))
+	clock.Advance(30 * time.Minute)
+	must(s.CreateTask("coding", nil, nil, nil))
+	clock.Advance(time.Hour)
+
+	// restarting a task keeps its tags
+	must(s.StartStopTask(1))
+	clock.Advance(15 * time.Minute)
+	must(s.Repo.CloseOpenedTasks())
+
+	tasks, err := s.GetTasksByDate(clock.Now())
+	must(err)
+	tags, err := s.GetTaskTags(tasks)
+	must(err)
+	if !slices.Equal(tags[3], []string{"daily", "meeting"}) {
+		t.Errorf("expected the restarted task to keep its tags, got %v", tags[3])
+	}
+	if meetings := TasksWithTag(tasks, tags, "#meeting"); len(meetings) != 2 {
+		t.Errorf("expected 2 meetings, got %+v", meetings)
+	}
+
+	// the #tags of the description are kept
+	must(s.SetTaskTags(2, []string{"focus"}))
+	must(s.SetTaskTags(1, nil))
+
+	durations, err := s.GetTagDurations(clock.Now(), clock.Now())
+	must(err)
+	expected := []TagDuration{
+		{Date: "2025-02-26", Tag: "daily", Duration: 900},
+		{Date: "2025-02-26", Tag: "focus", Duration: 3600},
+		{Date: "2025-02-26", Tag: "meeting", Duration: 2700},
+	}
+	if !slices.Equal(durations, expected) {
+		t.Errorf("expected %+v, got %+v", expected, durations)
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"time"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/repository"
+)
+
+func (s *Service) GetTrash() ([]model.TrashEntry, error) {
+	return s.Repo.GetTrash()
+}
+
+// TrashedTask decodes the task kept in a trash entry.
+func (s *Service) TrashedTask(entry model.TrashEntry) (*model.Task, error) {
+	return repository.ParseTaskSnapshot(entry.Task)
+}
+
+func (s *Service) RestoreFromTrash(entry model.TrashEntry) error {
+	return s.track(func() error {
+		return s.Repo.RestoreFromTrash(entry.ID)
+	})
+}
+
+func (s *Service) PurgeTrashEntry(entry model.TrashEntry) error {
+	return s.Repo.PurgeTrashEntry(entry.ID)
+}
+
+// PurgeExpiredTrash removes the tasks deleted more than the given days ago.
+// Zero or less keeps them forever.
+func (s *Service) PurgeExpiredTrash(days int) (int, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	return s.Repo.PurgeTrash(time.Now().AddDate(0, 0, -days))
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+const REQUEST_TIMEOUT = 30 * time.Second
+
+type Webhook struct {
+	Url             string            `json:"url"`
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	SuccessStatus   []int             `json:"success_status"`
+	SuccessPath     string            `json:"success_path"`
+	SuccessValue    string            `json:"success_value"`
+	IdPath          string            `json:"id_path"`
+	Activities      []types.Activity  `json:"activities"`
+	DefaultActivity int               `json:"default_activity"`
+
+	url  *template.Template
+	body *template.Template
+}
+
+// TemplateData is what the url and body templates are rendered with.
+type TemplateData struct {
+	types.TasksToSync
+	ActivityId int
+	Hours      float64
+}
+
+func NewWebhook(config []byte) (*Webhook, error) {
+	var webhook Webhook
+	if err := json.Unmarshal(config, &webhook); err != nil {
+		return nil, err
+	}
+
+	if webhook.Url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+
+	if webhook.Method == "" {
+		webhook.Method = http.MethodPost
+	}
+
+	var err error
+	webhook.url, err = template.New("url").Funcs(templateFuncs).Parse(webhook.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url template: %w", err)
+	}
+
+	webhook.body, err = template.New("body").Funcs(templateFuncs).Parse(webhook.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook body template: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"join": strings.Join,
+}
+
+func (w *Webhook) LoadActivities(ctx context.Context, task types.TasksToSync) (*[]types.Activity, *types.Activity, error) {
+	activities := w.Activities
+	if len(activities) == 0 {
+		activities = []types.Activity{{Id: w.DefaultActivity, Name: "Default"}}
+	}
+
+	var defaultActivity types.Activity
+	for _, activity := range activities {
+		if activity.Id == w.DefaultActivity {
+			defaultActivity = activity
+		}
+	}
+
+	return &activities, &defaultActivity, nil
+}
+
+// render executes the url and body templates for a task group.
+func (w *Webhook) render(task types.TasksToSync, activityId int) (string, string, error) {
+	data := TemplateData{
+		TasksToSync: task,
+		ActivityId:  activityId,
+		Hours:       float64(task.Duration) / 3600,
+	}
+
+	var url, body bytes.Buffer
+	if err := w.url.Execute(&url, data); err != nil {
+		return "", "", err
+	}
+	if err := w.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+
+	return url.String(), body.String(), nil
+}
+
+func (w *Webhook) PreviewTask(task types.TasksToSync, activityId int) (*types.RequestPreview, error) {
+	url, body, err := w.render(task, activityId)
+	if err != nil {
+		return nil, err
+	}
+	return &types.RequestPreview{Method: w.Method, Url: url, Body: body}, nil
+}
+
+func (w *Webhook) SendTask(ctx context.Context, task types.TasksToSync, activityId int) (string, error) {
+	url, body, err := w.render(task, activityId)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.Method, url, strings.NewReader(body))
+	if err != nil {
+		log.Println("Error creating request:", err)
+		return "", err
+	}
+
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: REQUEST_TIMEOUT}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Println("Error calling webhook:", err)
+		return "", err
+	}
+
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Println("Error reading response body:", err)
+		return "", err
+	}
+
+	if err := w.checkResponse(resp.StatusCode, respBody); err != nil {
+		return "", err
+	}
+
+	return w.remoteId(respBody), nil
+}
+
+func (w *Webhook) checkResponse(status int, body []byte) error {
+	if len(w.SuccessStatus) > 0 {
+		if !slices.Contains(w.SuccessStatus, status) {
+			return fmt.Errorf("unexpected status code: %d", status)
+		}
+	} else if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status code: %d", status)
+	}
+
+	if w.SuccessPath == "" {
+		return nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return fmt.Errorf("invalid JSON response: %w", err)
+	}
+
+	value, found := lookup(decoded, w.SuccessPath)
+	if !found {
+		return fmt.Errorf("path %s not found in response", w.SuccessPath)
+	}
+
+	if w.SuccessValue != "" {
+		if fmt.Sprint(value) != w.SuccessValue {
+			return fmt.Errorf("unexpected value at %s: %v", w.SuccessPath, value)
+		}
+		return nil
+	}
+
+	if !truthy(value) {
+		return fmt.Errorf("unexpected value at %s: %v", w.SuccessPath, value)
+	}
+
+	return nil
+}
+
+// remoteId extracts the id of the created entry from the response, when an
+// id path has been configured.
+func (w *Webhook) remoteId(body []byte) string {
+	if w.IdPath == "" {
+		return ""
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return ""
+	}
+
+	value, found := lookup(decoded, w.IdPath)
+	if !found || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
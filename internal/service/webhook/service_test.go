@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/francescarpi/mytime/internal/types"
+)
+
+func TestSendTask(t *testing.T) {
+	var gotBody, gotPath, gotHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Get("X-Token")
+		w.Write([]byte(`{"result": {"ok": true, "items": [{"id": 7}]}}`))
+	}))
+	defer server.Close()
+
+	config := `{
+		"url": "` + server.URL + `/issues/{{.ExternalId}}",
+		"headers": {"X-Token": "secret"},
+		"body": "{\"desc\": {{json .Desc}}, \"hours\": {{.Hours}}, \"ids\": \"{{join .Ids.IDs \",\"}}\"}",
+		"success_path": "result.ok",
+		"id_path": "result.items.0.id"
+	}`
+
+	webhook, err := NewWebhook([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := types.TasksToSync{
+		ExternalId: "42",
+		Desc:       `Fix "bug"`,
+		Duration:   5400,
+		Ids:        types.ListOfIds{IDs: []string{"1", "2"}},
+	}
+
+	remoteId, err := webhook.SendTask(context.Background(), task, 9)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if remoteId != "7" {
+		t.Errorf("Expected remote id 7, got %v", remoteId)
+	}
+
+	expectedBody := `{"desc": "Fix \"bug\"", "hours": 1.5, "ids": "1,2"}`
+	if gotBody != expectedBody {
+		t.Errorf("Expected body %v, got %v", expectedBody, gotBody)
+	}
+	if gotPath != "/issues/42" {
+		t.Errorf("Expected path /issues/42, got %v", gotPath)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("Expected header secret, got %v", gotHeader)
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		webhook  Webhook
+		status   int
+		body     string
+		expectOk bool
+	}{
+		{Webhook{}, 200, "", true},
+		{Webhook{}, 500, "", false},
+		{Webhook{SuccessStatus: []int{202}}, 200, "", false},
+		{Webhook{SuccessStatus: []int{202}}, 202, "", true},
+		{Webhook{SuccessPath: "ok"}, 200, `{"ok": true}`, true},
+		{Webhook{SuccessPath: "ok"}, 200, `{"ok": false}`, false},
+		{Webhook{SuccessPath: "ok"}, 200, `{}`, false},
+		{Webhook{SuccessPath: "items.0.status", SuccessValue: "created"}, 200, `{"items": [{"status": "created"}]}`, true},
+		{Webhook{SuccessPath: "items.1.status"}, 200, `{"items": [{"status": "created"}]}`, false},
+	}
+
+	for _, test := range tests {
+		err := test.webhook.checkResponse(test.status, []byte(test.body))
+		if (err == nil) != test.expectOk {
+			t.Errorf("Expected ok=%v for status %v and body %v, got %v", test.expectOk, test.status, test.body, err)
+		}
+	}
+}
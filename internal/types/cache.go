@@ -0,0 +1,10 @@
+package types
+
+import "github.com/francescarpi/mytime/internal/model"
+
+// Cache stores integration data locally, so it can be reused between requests
+// and is still available offline.
+type Cache interface {
+	GetCacheEntry(key string) (*model.CacheEntry, error)
+	SaveCacheEntry(key, value string) error
+}
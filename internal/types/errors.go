@@ -0,0 +1,23 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors integrations return so the UI can tell the user what went wrong.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// ValidationError is returned when the backend rejects the data sent.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Errors, ", ")
+}
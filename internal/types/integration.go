@@ -0,0 +1,81 @@
+package types
+
+type Activity struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Issue struct {
+	ExternalId string `json:"external_id"`
+	Subject    string `json:"subject"`
+	Project    string `json:"project"`
+	Status     string `json:"status"`
+	Cached     bool   `json:"-"`
+}
+
+// ISSUES_PAGE_SIZE is the number of issues listed when the filter sets no
+// limit.
+const ISSUES_PAGE_SIZE = 25
+
+type IssueFilter struct {
+	Instance string
+	Status   string
+	Project  string
+	Text     string
+	Offset   int
+	Limit    int
+}
+
+type TimeEntry struct {
+	Id          string
+	Integration string
+	ExternalId  string
+	Date        string
+	Duration    int
+	Comments    string
+	Activity    string
+}
+
+// OutboxPayload is what is stored in the outbox to send a sync group later:
+// the ids of its tasks, as the group is built again from them when sent, and
+// the activity and custom fields chosen.
+type OutboxPayload struct {
+	TaskIds      []string           `json:"task_ids"`
+	ActivityId   int                `json:"activity_id"`
+	CustomFields []CustomFieldValue `json:"custom_fields,omitempty"`
+}
+
+// RequestPreview is the request an integration would send for a sync group.
+type RequestPreview struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	Body   string `json:"body"`
+}
+
+// CustomField is a field the integration accepts, or requires, on the time
+// entries. Value holds the default to send.
+type CustomField struct {
+	Id             int      `json:"id"`
+	Name           string   `json:"name"`
+	Required       bool     `json:"required"`
+	PossibleValues []string `json:"possible_values"`
+	Value          string   `json:"value"`
+}
+
+type CustomFieldValue struct {
+	Id    int    `json:"id"`
+	Value string `json:"value"`
+}
+
+type IssueStatus struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// IssueUpdate holds the changes made to an issue after syncing. Zero values
+// are left unchanged.
+type IssueUpdate struct {
+	StatusId  int
+	DoneRatio *int
+	Notes     string
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"database/sql/driver"
 	"strings"
+	"time"
 )
 
 type ListOfIds struct {
@@ -22,14 +23,20 @@ func (i *ListOfIds) Scan(value any) error {
 	return nil
 }
 
+// TasksToSync is a group of tasks sent as a single entry. Duration is the one
+// reported, after rounding, and RawDuration the one tracked locally. Notes
+// joins the notes of the tasks of the group.
 type TasksToSync struct {
-	Id         string
-	ExternalId string
-	Duration   int
-	Desc       string
-	Date       string
-	Project    string
-	Ids        ListOfIds
+	Id           string
+	ExternalId   string
+	Duration     int
+	RawDuration  int
+	Desc         string
+	Date         string
+	Project      string
+	Ids          ListOfIds
+	CustomFields []CustomFieldValue `gorm:"-"`
+	Notes        string             `gorm:"-"`
 }
 
 type TaskStatus int64
@@ -39,3 +46,10 @@ const (
 	NotReported
 	All
 )
+
+// DateRange limits a query to the days between From and To, both included. A
+// zero date leaves that side open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
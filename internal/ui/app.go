@@ -2,14 +2,15 @@ package ui
 
 import "github.com/rivo/tview"
 
-func StartApp() {
+func StartApp(profile string) error {
+	deps, err := NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+
 	app := tview.NewApplication()
 	pages := tview.NewPages()
-	deps := InitDeps()
-
 	pages.AddPage("home", HomeView(app, pages, deps), true, true)
 
-	if err := app.SetRoot(pages, true).EnableMouse(true).Run(); err != nil {
-		panic(err)
-	}
+	return app.SetRoot(pages, true).EnableMouse(true).Run()
 }
@@ -1,25 +1,64 @@
 package ui
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/francescarpi/mytime/internal/config"
 	"github.com/francescarpi/mytime/internal/repository"
 	"github.com/francescarpi/mytime/internal/service"
-	"github.com/francescarpi/mytime/internal/service/redmine"
+	"github.com/francescarpi/mytime/internal/service/integration"
 )
 
 type Dependencies struct {
-	Service *service.Service
-	Redmine *redmine.Redmine
+	Profile     string
+	Service     *service.Service
+	Integration *integration.Router
 }
 
-func InitDeps() *Dependencies {
-	cfg := config.Load()
-	repo := repository.NewSqliteRepository(cfg.DBUrl)
+// SwitchProfile replaces the dependencies with those of the profile, closing
+// the database of the current one. They are left as they were on error.
+func (d *Dependencies) SwitchProfile(profile string) error {
+	deps, err := NewDependencies(profile)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Service.Repo.Close(); err != nil {
+		log.Println("Error closing the database:", d.Profile, err)
+	}
+	*d = *deps
+	return nil
+}
+
+// NewDependencies opens the database of the profile and sets up the
+// integrations.
+func NewDependencies(profile string) (*Dependencies, error) {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repository.New(cfg.DBUrl)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", cfg.Profile, err)
+	}
 	service := &service.Service{Repo: repo}
-	redmine := redmine.NewRedmine(service)
+	if purged, err := service.PurgeExpiredTrash(cfg.TrashDays); err != nil {
+		log.Println("Error purging the trash:", err)
+	} else if purged > 0 {
+		log.Printf("Purged %d tasks from the trash", purged)
+	}
 
-	return &Dependencies{
-		Service: service,
-		Redmine: redmine,
+	integration, err := integration.New(service)
+	if err != nil {
+		repo.Close()
+		return nil, err
 	}
+
+	return &Dependencies{
+		Profile:     cfg.Profile,
+		Service:     service,
+		Integration: integration,
+	}, nil
 }
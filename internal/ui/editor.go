@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+const DEFAULT_EDITOR = "vi"
+
+// editInEditor opens the text in $VISUAL or $EDITOR, suspending the TUI until
+// the editor exits, and returns the text saved.
+func editInEditor(app *tview.Application, text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = DEFAULT_EDITOR
+	}
+
+	file, err := os.CreateTemp("", "mytime-notes-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return "", errors.New("no editor set")
+	}
+
+	var runErr error
+	app.Suspend(func() {
+		cmd := exec.Command(args[0], append(args[1:], file.Name())...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		runErr = cmd.Run()
+	})
+	if runErr != nil {
+		return "", runErr
+	}
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
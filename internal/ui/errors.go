@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"context"
+	"errors"
+
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/rivo/tview"
+)
+
+// errorLabel returns a short description of an integration error to be shown
+// in a table cell.
+func errorLabel(err error) string {
+	var validationErr *types.ValidationError
+
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		return "Not found"
+	case errors.Is(err, types.ErrForbidden):
+		return "Forbidden"
+	case errors.Is(err, types.ErrUnauthorized):
+		return "Unauthorized"
+	case errors.Is(err, types.ErrRateLimited):
+		return "Rate limited"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Timeout"
+	case errors.Is(err, context.Canceled):
+		return "Cancelled"
+	case errors.As(err, &validationErr):
+		return tview.Escape(validationErr.Error())
+	}
+	return "Connection Error!"
+}
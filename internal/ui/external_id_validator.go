@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/service/integration"
+	"github.com/rivo/tview"
+)
+
+const VALIDATION_DELAY = 500 * time.Millisecond
+
+// ExternalIdValidator checks the external id typed in a form against the
+// integration, once the user stops typing, and shows the issue found.
+type ExternalIdValidator struct {
+	app        *tview.Application
+	deps       *Dependencies
+	info       *tview.TextView
+	project    *tview.InputField
+	generation atomic.Int64
+	cancel     context.CancelFunc
+}
+
+func NewExternalIdValidator(app *tview.Application, deps *Dependencies, form *tview.Form) *ExternalIdValidator {
+	form.AddTextView("Issue: ", "", 0, 2, true, false)
+
+	return &ExternalIdValidator{
+		app:     app,
+		deps:    deps,
+		info:    form.GetFormItemByLabel("Issue: ").(*tview.TextView),
+		project: form.GetFormItemByLabel("Project: ").(*tview.InputField),
+	}
+}
+
+func (v *ExternalIdValidator) Validate(externalId string) {
+	generation := v.generation.Add(1)
+	externalId = strings.TrimSpace(externalId)
+
+	if v.cancel != nil {
+		v.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+
+	if externalId == "" {
+		v.info.SetText("")
+		return
+	}
+
+	v.info.SetText("[gray]Checking...")
+	project := v.project.GetText()
+
+	go func() {
+		time.Sleep(VALIDATION_DELAY)
+		if v.generation.Load() != generation {
+			return
+		}
+
+		issue, err := v.deps.Integration.ValidateId(ctx, externalId, project)
+
+		v.app.QueueUpdateDraw(func() {
+			if v.generation.Load() != generation {
+				return
+			}
+
+			if errors.Is(err, integration.ErrNotSupported) {
+				v.info.SetText("")
+				return
+			}
+
+			if err != nil {
+				log.Println("Error validating external id:", err)
+				v.info.SetText("[red]" + errorLabel(err))
+				return
+			}
+
+			text := fmt.Sprintf("[green]%s[-]\n%s · %s", tview.Escape(issue.Subject), tview.Escape(issue.Project), tview.Escape(issue.Status))
+			if issue.Cached {
+				text += " [gray](cached)"
+			}
+			v.info.SetText(text)
+
+			if v.project.GetText() == "" && issue.Project != "" {
+				v.project.SetText(issue.Project)
+			}
+		})
+	}()
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/francescarpi/mytime/internal/ui/components"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -118,12 +119,99 @@ func homeViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 			return tasksToSync > 0
 		},
 		func() {
+			state.Cancel()
 			pages.
 				RemovePage("home").
 				AddPage("sync", SyncView(app, pages, deps), true, true)
 		},
 	)
 
+	issuesView := GetNewAction("Issues", NewRuneKey("i", 'i'),
+		func() bool { return deps.Integration.CanListMyIssues() },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("home").
+				AddPage("issues", IssuesView(app, pages, deps), true, true)
+		},
+	)
+
+	reconcileView := GetNewAction("Reconcile", NewRuneKey("c", 'c'),
+		func() bool { return deps.Integration.CanListMyTimeEntries() },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("home").
+				AddPage("reconcile", ReconcileView(app, pages, deps, state.Date), true, true)
+		},
+	)
+
+	trashView := GetNewAction("Trash", NewRuneKey("T", 'T'),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("home").
+				AddPage("trash", TrashView(app, pages, deps), true, true)
+		},
+	)
+
+	projectsView := GetNewAction("Projects", NewRuneKey("p", 'p'),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("home").
+				AddPage("projects", ProjectsView(app, pages, deps), true, true)
+		},
+	)
+
+	notes := GetNewAction("Notes", NewRuneKey("e", 'e'),
+		func() bool {
+			_, err := getNotesTask(state)
+			return err == nil
+		},
+		func() {
+			task, _ := getNotesTask(state)
+			showNotesModal(app, pages, state, task, deps)
+		},
+	)
+
+	notesEditor := GetNewAction("Notes in $EDITOR", NewRuneKey("E", 'E'),
+		func() bool {
+			_, err := getNotesTask(state)
+			return err == nil
+		},
+		func() {
+			task, _ := getNotesTask(state)
+			editNotesInEditor(app, pages, state, task, deps)
+		},
+	)
+
+	searchView := GetNewAction("Search", NewRuneKey("/", '/'),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("home").
+				AddPage("search", SearchView(app, pages, deps), true, true)
+		},
+	)
+
+	filterTag := GetNewAction("Filter Tag", NewRuneKey("f", 'f'),
+		func() bool { return true },
+		func() {
+			showFilterTagModal(app, pages, state, deps)
+		},
+	)
+
+	switchProfile := GetNewAction("Profile", NewRuneKey("P", 'P'),
+		func() bool { return true },
+		func() {
+			showProfileModal(app, pages, state, deps)
+		},
+	)
+
 	summaryAction := GetNewAction("Summary", NewRuneKey("y", 'y'),
 		func() bool { return true },
 		func() {
@@ -142,6 +230,26 @@ func homeViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 		},
 	)
 
+	undo := GetNewAction("Undo", NewRuneKey("u", 'u'),
+		func() bool { return deps.Service.CanUndo() },
+		func() {
+			if err := deps.Service.Undo(); err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error undoing the change: %s", err.Error()), nil)
+			}
+			state.Render()
+		},
+	)
+
+	redo := GetNewAction("Redo", NewSpecialKey("Ctrl-R", tcell.KeyCtrlR),
+		func() bool { return deps.Service.CanRedo() },
+		func() {
+			if err := deps.Service.Redo(); err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error redoing the change: %s", err.Error()), nil)
+			}
+			state.Render()
+		},
+	)
+
 	return &[]Action{
 		quitAction,
 		prevDay,
@@ -153,9 +261,20 @@ func homeViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 		startStop,
 		duplicate,
 		modify,
+		notes,
+		notesEditor,
 		deleteAction,
 		syncView,
+		issuesView,
+		reconcileView,
+		trashView,
+		projectsView,
+		searchView,
+		filterTag,
+		switchProfile,
 		summaryAction,
 		markAsReport,
+		undo,
+		redo,
 	}
 }
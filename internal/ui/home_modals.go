@@ -1,10 +1,17 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/francescarpi/mytime/internal/config"
 	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/types"
 	"github.com/francescarpi/mytime/internal/ui/components"
 	"github.com/francescarpi/mytime/internal/util"
 	"github.com/rivo/tview"
@@ -16,27 +23,37 @@ func showNewTaskModal(
 	state *HomeState,
 	deps *Dependencies,
 ) {
-	var description string
+	var description, tags string
 	var project, externalId *string
+	var validator *ExternalIdValidator
 
 	form := tview.NewForm().
 		AddInputField("Project: ", "", 0, nil, func(text string) { project = &text }).
 		AddInputField("Description", "", 0, nil, func(text string) { description = text }).
-		AddInputField("External Id", "", 0, nil, func(text string) { externalId = &text })
+		AddInputField("Tags", "", 0, nil, func(text string) { tags = text }).
+		AddInputField("External Id", "", 0, nil, func(text string) {
+			externalId = &text
+			validator.Validate(text)
+		})
+	validator = NewExternalIdValidator(app, deps, form)
+	setProjectAutocomplete(form, deps)
+	setTagsAutocomplete(form, "Tags", deps)
 
-	components.ShowFormModal("New Task", 80, 11, form, pages, app, func() {
+	components.ShowFormModal("New Task", 80, 16, form, pages, app, func() {
 		if description == "" {
 			components.ShowAlertModal(app, pages, "Description cannot be empty", nil)
 			return
 		}
 
-		// Call service to create task
-		err := deps.Service.CreateTask(description, project, externalId)
-		if err != nil {
-			components.ShowAlertModal(app, pages, fmt.Sprintf("Error creating task: %s", err.Error()), nil)
-			return
-		}
-		state.RenderAndGotoToday()
+		confirmProject(app, pages, deps, project, func() {
+			// Call service to create task
+			err := deps.Service.CreateTask(description, project, externalId, util.ParseTags(tags))
+			if err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error creating task: %s", err.Error()), nil)
+				return
+			}
+			state.RenderAndGotoToday()
+		})
 	}, nil)
 
 }
@@ -53,19 +70,33 @@ func showDeleteTaskModal(
 		app,
 		pages,
 		"deleteTaskModal",
-		fmt.Sprintf("Are you sure you want to delete the task?\n\n%s", task.Desc),
+		fmt.Sprintf("Are you sure you want to move the task to the trash?\n\n%s", task.Desc),
 		[]string{"Cancel", "Ok"},
 		func(button string) {
 			state.Table.SetDisableAutomaticDeselect(false)
 			state.Table.Deselect()
 			if button == "Ok" {
 				log.Printf("Deleting task %d", task.ID)
+				entry := deps.Service.GetRemoteEntry(task.ID)
+				var previous types.TasksToSync
+				if entry != nil {
+					previous, _, _ = deps.Service.GetRemoteEntryGroup(entry)
+				}
+
 				err := deps.Service.DeleteTask(task.ID)
 				if err != nil {
 					components.ShowAlertModal(app, pages, fmt.Sprintf("Error deleting task: %s", err.Error()), nil)
 					return
 				}
 				state.Render()
+
+				if entry != nil && deps.Integration.CanEditEntry(entry) {
+					message := "The task was already synced. Do you want to delete the remote time entry too?"
+					if len(previous.Ids.IDs) > 1 {
+						message = "The task was already synced with other tasks. Do you want to update the remote time entry too?"
+					}
+					showAmendRemoteEntryModal(app, pages, state, deps, entry, message)
+				}
 			}
 		},
 	)
@@ -89,10 +120,18 @@ func showModifyTaskModal(
 		externalId = *task.ExternalId
 	}
 
+	tags := util.FormatTags(state.TaskTags[task.ID])
+
+	var validator *ExternalIdValidator
+
 	form := tview.NewForm().
-		AddInputField("Project: ", *task.Project, 0, nil, func(text string) { task.Project = &text }).
+		AddInputField("Project: ", task.ProjectName(), 0, nil, func(text string) { task.Project = &text }).
 		AddInputField("Description", task.Desc, 0, nil, func(text string) { task.Desc = text }).
-		AddInputField("External Id", externalId, 0, nil, func(text string) { task.ExternalId = &text }).
+		AddInputField("Tags", tags, 0, nil, func(text string) { tags = text }).
+		AddInputField("External Id", externalId, 0, nil, func(text string) {
+			task.ExternalId = &text
+			validator.Validate(text)
+		}).
 		AddInputField("Started", task.Start.Format("15:04"), 0, nil, func(text string) {
 			newTime, err := util.UpdateTime(&task.Start.Time, text)
 			if err != nil {
@@ -107,9 +146,13 @@ func showModifyTaskModal(
 			}
 			task.End = &model.LocalTimestamp{Time: newTime}
 		})
+	validator = NewExternalIdValidator(app, deps, form)
+	validator.Validate(externalId)
+	setProjectAutocomplete(form, deps)
+	setTagsAutocomplete(form, "Tags", deps)
 
 	state.Table.SetDisableAutomaticDeselect(true)
-	components.ShowFormModal("Modify Task", 80, 15, form, pages, app, func() {
+	components.ShowFormModal("Modify Task", 80, 20, form, pages, app, func() {
 		if task.Desc == "" {
 			components.ShowAlertModal(app, pages, "Description cannot be empty", nil)
 			return
@@ -119,13 +162,23 @@ func showModifyTaskModal(
 			task.ExternalId = nil
 		}
 
-		// Call service to update task
-		err := deps.Service.UpdateTask(&task)
-		if err != nil {
-			components.ShowAlertModal(app, pages, fmt.Sprintf("Error updating task: %s", err.Error()), nil)
-			return
-		}
-		state.Render()
+		confirmProject(app, pages, deps, task.Project, func() {
+			// Call service to update task
+			err := deps.Service.UpdateTask(&task)
+			if err == nil {
+				err = deps.Service.SetTaskTags(task.ID, util.ParseTags(tags))
+			}
+			if err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error updating task: %s", err.Error()), nil)
+				return
+			}
+			state.Render()
+
+			if entry := deps.Service.GetRemoteEntry(task.ID); entry != nil && deps.Integration.CanEditEntry(entry) {
+				showAmendRemoteEntryModal(app, pages, state, deps, entry,
+					"The task was already synced. Do you want to update the remote time entry too?")
+			}
+		})
 	}, func() {
 		state.Table.SetDisableAutomaticDeselect(false)
 		state.Table.Deselect()
@@ -146,20 +199,24 @@ func showDuplicateTaskModal(
 		externalId = *task.ExternalId
 	}
 
+	tags := util.FormatTags(state.TaskTags[task.ID])
+
 	form := tview.NewForm().
-		AddTextView("Project: ", *task.Project, 0, 1, false, false).
+		AddTextView("Project: ", task.ProjectName(), 0, 1, false, false).
 		AddTextView("External ID: ", externalId, 0, 1, false, false).
-		AddInputField("Description: ", "", 0, nil, func(text string) { task.Desc = text })
+		AddInputField("Description: ", "", 0, nil, func(text string) { task.Desc = text }).
+		AddInputField("Tags: ", tags, 0, nil, func(text string) { tags = text })
+	setTagsAutocomplete(form, "Tags: ", deps)
 
 	state.Table.SetDisableAutomaticDeselect(true)
-	components.ShowFormModal("Duplicate Task", 80, 11, form, pages, app, func() {
+	components.ShowFormModal("Duplicate Task", 80, 13, form, pages, app, func() {
 		if task.Desc == "" {
 			components.ShowAlertModal(app, pages, "Description cannot be empty", nil)
 			return
 		}
 
 		// Call service to create duplicated task
-		err := deps.Service.CreateTask(task.Desc, task.Project, task.ExternalId)
+		err := deps.Service.CreateTask(task.Desc, task.Project, task.ExternalId, util.ParseTags(tags))
 		if err != nil {
 			components.ShowAlertModal(app, pages, fmt.Sprintf("Error creating task: %s", err.Error()), nil)
 			return
@@ -171,6 +228,191 @@ func showDuplicateTaskModal(
 	})
 }
 
+// confirmProject saves a task form when its project is empty or known, and
+// otherwise asks before creating the project, so a typo does not split it.
+func confirmProject(app *tview.Application, pages *tview.Pages, deps *Dependencies, project *string, save func()) {
+	if project == nil || *project == "" || deps.Service.GetProject(*project) != nil {
+		save()
+		return
+	}
+
+	components.ShowConfirmModal(
+		app,
+		pages,
+		"confirmProjectModal",
+		fmt.Sprintf("The project %s does not exist yet. Do you want to create it?", *project),
+		[]string{"Cancel", "Create"},
+		func(button string) {
+			if button != "Create" {
+				return
+			}
+			if err := deps.Service.SaveProject(&model.Project{Name: *project}); err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error creating project: %s", err.Error()), nil)
+				return
+			}
+			save()
+		},
+	)
+}
+
+// setProjectAutocomplete suggests the active projects in the project field of
+// a task form.
+func setProjectAutocomplete(form *tview.Form, deps *Dependencies) {
+	field, ok := form.GetFormItemByLabel("Project: ").(*tview.InputField)
+	if !ok {
+		return
+	}
+
+	projects, err := deps.Service.GetProjects()
+	if err != nil {
+		log.Println("Error loading projects:", err)
+		return
+	}
+
+	field.SetAutocompleteUseTags(false).SetAutocompleteFunc(func(text string) []string {
+		if text == "" {
+			return nil
+		}
+
+		var entries []string
+		for _, project := range projects {
+			if !project.Archived && project.Name != text && strings.HasPrefix(strings.ToLower(project.Name), strings.ToLower(text)) {
+				entries = append(entries, project.Name)
+			}
+		}
+		return entries
+	})
+}
+
+// setTagsAutocomplete suggests the known tags for the last one typed in the
+// tags field of a form.
+func setTagsAutocomplete(form *tview.Form, label string, deps *Dependencies) {
+	field, ok := form.GetFormItemByLabel(label).(*tview.InputField)
+	if !ok {
+		return
+	}
+
+	tags, err := deps.Service.GetTags()
+	if err != nil {
+		log.Println("Error loading tags:", err)
+		return
+	}
+
+	field.SetAutocompleteUseTags(false).SetAutocompleteFunc(func(text string) []string {
+		cut := strings.LastIndexAny(text, ", ") + 1
+		typed := strings.ToLower(strings.TrimLeft(text[cut:], "#"))
+		if typed == "" {
+			return nil
+		}
+
+		var entries []string
+		for _, tag := range tags {
+			if tag.Name != typed && strings.HasPrefix(tag.Name, typed) {
+				entries = append(entries, text[:cut]+tag.Name)
+			}
+		}
+		return entries
+	})
+}
+
+func showNotesModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	state *HomeState,
+	task model.Task,
+	deps *Dependencies,
+) {
+	notes := state.Notes[task.ID]
+
+	form := tview.NewForm().
+		AddTextArea("Notes", notes, 0, 14, 0, func(text string) { notes = text })
+
+	state.Table.SetDisableAutomaticDeselect(true)
+	components.ShowFormModal(fmt.Sprintf("Notes of #%d %s", task.ID, tview.Escape(task.Desc)), 90, 20, form, pages, app, func() {
+		if err := deps.Service.SaveNotes(task.ID, notes); err != nil {
+			components.ShowAlertModal(app, pages, fmt.Sprintf("Error saving the notes: %s", err.Error()), nil)
+			return
+		}
+		state.Render()
+	}, func() {
+		state.Table.SetDisableAutomaticDeselect(false)
+	})
+}
+
+func editNotesInEditor(
+	app *tview.Application,
+	pages *tview.Pages,
+	state *HomeState,
+	task model.Task,
+	deps *Dependencies,
+) {
+	notes, err := editInEditor(app, state.Notes[task.ID])
+	if err == nil {
+		err = deps.Service.SaveNotes(task.ID, notes)
+	}
+	if err != nil {
+		log.Println("Error editing notes:", task.ID, err)
+		components.ShowAlertModal(app, pages, fmt.Sprintf("Error editing the notes: %s", err.Error()), nil)
+		return
+	}
+	state.Render()
+}
+
+func showFilterTagModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	state *HomeState,
+	deps *Dependencies,
+) {
+	tag := state.Tag
+
+	form := tview.NewForm().
+		AddInputField("Tag", tag, 0, nil, func(text string) { tag = text })
+	setTagsAutocomplete(form, "Tag", deps)
+
+	components.ShowFormModal("Filter by Tag (empty shows all)", 60, 7, form, pages, app, func() {
+		state.Tag = ""
+		if tags := util.ParseTags(tag); len(tags) > 0 {
+			state.Tag = tags[0]
+		}
+		state.Table.Deselect()
+		state.Render()
+	}, nil)
+}
+
+func showProfileModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	state *HomeState,
+	deps *Dependencies,
+) {
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		components.ShowAlertModal(app, pages, fmt.Sprintf("Error loading the profiles: %s", err.Error()), nil)
+		return
+	}
+
+	names := profiles.Names()
+	selected := max(slices.Index(names, deps.Profile), 0)
+	profile := names[selected]
+
+	form := tview.NewForm().
+		AddDropDown("Profile", names, selected, func(option string, index int) { profile = option })
+
+	components.ShowFormModal("Switch Profile", 60, 7, form, pages, app, func() {
+		if profile == deps.Profile {
+			return
+		}
+		if err := deps.SwitchProfile(profile); err != nil {
+			log.Println("Error switching profile:", profile, err)
+			components.ShowAlertModal(app, pages, fmt.Sprintf("Error switching profile: %s", err.Error()), nil)
+			return
+		}
+		state.Cancel()
+		pages.AddPage("home", HomeView(app, pages, deps), true, true)
+	}, nil)
+}
+
 func showSummaryModal(
 	app *tview.Application,
 	pages *tview.Pages,
@@ -185,7 +427,32 @@ func showSummaryModal(
 	form := tview.NewForm().
 		AddTextView("Reported: ", summary.Reported, 0, 1, false, false).
 		AddTextView("Not Reported: ", summary.NotReported, 0, 1, false, false)
-	components.ShowFormModal("Summary", 80, 11, form, pages, app, nil, nil)
+
+	monday, sunday := util.WeekBounds(state.Date)
+	durations, err := deps.Service.GetTagDurations(monday, sunday)
+	if err != nil {
+		log.Println("Error loading tag durations:", err)
+	}
+
+	// time of each tag in the day and in the week
+	var tags []string
+	daily, weekly := map[string]int{}, map[string]int{}
+	for _, duration := range durations {
+		tags = append(tags, duration.Tag)
+		weekly[duration.Tag] += duration.Duration
+		if duration.Date == state.Date.Format(time.DateOnly) {
+			daily[duration.Tag] += duration.Duration
+		}
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+
+	for _, tag := range tags {
+		text := fmt.Sprintf("%s (week: %s)", util.HumanizeDuration(daily[tag]), util.HumanizeDuration(weekly[tag]))
+		form.AddTextView("#"+tag+": ", text, 0, 1, false, false)
+	}
+
+	components.ShowFormModal("Summary", 80, 11+len(tags)*2, form, pages, app, nil, nil)
 }
 
 func showReportConfirmModal(
@@ -216,3 +483,38 @@ func showReportConfirmModal(
 		},
 	)
 }
+
+func showAmendRemoteEntryModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	state *HomeState,
+	deps *Dependencies,
+	entry *model.RemoteEntry,
+	message string,
+) {
+	components.ShowConfirmModal(
+		app,
+		pages,
+		"amendRemoteEntryModal",
+		message,
+		[]string{"Cancel", "Ok"},
+		func(button string) {
+			if button != "Ok" {
+				return
+			}
+
+			go func() {
+				err := deps.Integration.AmendRemoteEntry(state.Ctx, deps.Service, entry)
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				if err != nil {
+					log.Println("Error amending remote entry:", err)
+					app.QueueUpdateDraw(func() {
+						components.ShowAlertModal(app, pages, fmt.Sprintf("Error updating the remote time entry: %s", err.Error()), nil)
+					})
+				}
+			}()
+		},
+	)
+}
@@ -1,11 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service"
 	"github.com/francescarpi/mytime/internal/ui/components"
 	"github.com/francescarpi/mytime/internal/util"
 	"github.com/rivo/tview"
@@ -16,16 +19,24 @@ const REFRESH_RATE = 10
 type HomeState struct {
 	Date               time.Time
 	Tasks              []model.Task
+	Projects           map[string]model.Project
+	TaskTags           map[uint][]string
+	Tag                string
+	Notes              map[uint]string
+	Details            *tview.TextView
 	Table              *components.Table
 	Render             func()
 	RenderAndGotoToday func()
 	ActionsManager     *ActionsManager
+	Ctx                context.Context
+	Cancel             context.CancelFunc
 }
 
 func HomeView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
 	state := &HomeState{
 		Date: time.Now(),
 	}
+	state.Ctx, state.Cancel = context.WithCancel(context.Background())
 
 	state.RenderAndGotoToday = func() {
 		state.Date = time.Now()
@@ -33,7 +44,7 @@ func HomeView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 	}
 
 	header := tview.NewFlex().SetDirection(tview.FlexColumn)
-	header.SetTitle(" MyTime ").SetBorder(true)
+	header.SetTitle(fmt.Sprintf(" MyTime [yellow]%s[-] ", tview.Escape(deps.Profile))).SetBorder(true)
 
 	footer := tview.NewTextView()
 	footer.SetDynamicColors(true).SetBorder(true)
@@ -41,18 +52,28 @@ func HomeView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 	state.ActionsManager = GetNewActionsManager(footer, homeViewActions(app, pages, deps, state))
 	state.Table = components.GetNewTable(
 		app,
-		[]string{"ID", "Project", "Description", "Ext.ID", "Started", "Ended", "Duration", "Reported"},
+		[]string{"ID", "Project", "Description", "Tags", "Ext.ID", "Started", "Ended", "Duration", "Reported"},
 		func() {
 			app.QueueUpdateDraw(state.ActionsManager.Refresh)
 		},
 	)
 
 	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+	state.Table.GetTable().SetSelectionChangedFunc(func(row, column int) {
+		renderDetails(state)
+	})
+
+	state.Details = tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
+	state.Details.SetBorder(true).SetTitle(" Notes ")
+
+	body := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(state.Table.GetTable(), 0, 2, true).
+		AddItem(state.Details, 0, 1, false)
 
 	layout := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(header, 3, 0, false).
-		AddItem(state.Table.GetTable(), 0, 1, true).
-		AddItem(footer, 4, 0, false)
+		AddItem(body, 0, 1, true).
+		AddItem(footer, 5, 0, false)
 
 	state.Render = func() {
 		w, err := deps.Service.GetWorkedDuration(state.Date)
@@ -64,7 +85,30 @@ func HomeView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 		if err != nil {
 			panic(err)
 		}
-		state.Tasks = tasks
+		state.TaskTags, err = deps.Service.GetTaskTags(tasks)
+		if err != nil {
+			log.Println("Error loading tags:", err)
+		}
+		state.Tasks = service.TasksWithTag(tasks, state.TaskTags, state.Tag)
+		state.Notes, err = deps.Service.GetTaskNotes(tasks)
+		if err != nil {
+			log.Println("Error loading notes:", err)
+		}
+
+		if state.Tag != "" {
+			state.Table.SetTitle(fmt.Sprintf("Tasks tagged #%s", tview.Escape(state.Tag)))
+		} else {
+			state.Table.GetTable().SetTitle("")
+		}
+
+		projects, err := deps.Service.GetProjects()
+		if err != nil {
+			log.Println("Error loading projects:", err)
+		}
+		state.Projects = make(map[string]model.Project, len(projects))
+		for _, project := range projects {
+			state.Projects[project.Name] = project
+		}
 
 		header.Clear().
 			AddItem(formatHeaderSection("Today", w.Daily, w.DailyGoal, w.DailyOvertime), 0, 1, false).
@@ -72,6 +116,7 @@ func HomeView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 			AddItem(tview.NewTextView().SetTextAlign(tview.AlignRight).SetText(state.Date.Format("Monday, 2006-01-02")), 0, 1, false)
 
 		renderTasksTable(state)
+		renderDetails(state)
 		state.ActionsManager.Refresh()
 	}
 
@@ -111,6 +156,42 @@ func getSelectedTask(state *HomeState) (model.Task, error) {
 	return state.Tasks[row], nil
 }
 
+// getNotesTask returns the selected task, or else the open one, whose notes
+// are shown in the details pane.
+func getNotesTask(state *HomeState) (model.Task, error) {
+	if task, err := getSelectedTask(state); err == nil {
+		return task, nil
+	}
+	for _, task := range state.Tasks {
+		if task.IsOpen() {
+			return task, nil
+		}
+	}
+	return model.Task{}, fmt.Errorf("no task selected or open")
+}
+
+func renderDetails(state *HomeState) {
+	if state.Details == nil {
+		return
+	}
+
+	task, err := getNotesTask(state)
+	if err != nil {
+		state.Details.SetText("")
+		return
+	}
+
+	notes := state.Notes[task.ID]
+	if notes == "" {
+		notes = "[gray]No notes yet[-]"
+	} else {
+		notes = tview.Escape(notes)
+	}
+
+	state.Details.SetText(fmt.Sprintf("[yellow]#%d %s[-]\n\n%s", task.ID, tview.Escape(task.Desc), notes))
+	state.Details.ScrollToBeginning()
+}
+
 func renderTasksTable(state *HomeState) {
 	renderer := state.Table.GetRowRenderer()
 	for row, task := range state.Tasks {
@@ -121,19 +202,33 @@ func renderTasksTable(state *HomeState) {
 			externalId = *task.ExternalId
 		}
 
+		project := task.ProjectName()
+		if known, ok := state.Projects[project]; ok {
+			project = known.Label()
+		}
+
 		renderer(row, 0, fmt.Sprintf("%d", task.ID), 0, tview.AlignLeft)
-		renderer(row, 1, *task.Project, 0, tview.AlignLeft)
+		renderer(row, 1, project, 0, tview.AlignLeft)
 		renderer(row, 2, task.Desc, 1, tview.AlignLeft)
-		renderer(row, 3, externalId, 0, tview.AlignLeft)
-		renderer(row, 4, task.Start.Format("15:04"), 0, tview.AlignCenter)
+		renderer(row, 3, formatTags(state.TaskTags[task.ID]), 0, tview.AlignLeft)
+		renderer(row, 4, externalId, 0, tview.AlignLeft)
+		renderer(row, 5, task.Start.Format("15:04"), 0, tview.AlignCenter)
 
 		endFormatted := "🚗"
 		if task.End != nil {
 			endFormatted = task.End.Format("15:04")
 		}
 
-		renderer(row, 5, endFormatted, 0, tview.AlignCenter)
-		renderer(row, 6, util.HumanizeDuration(task.Duration), 0, tview.AlignRight)
-		renderer(row, 7, task.ReportedIcon(), 0, tview.AlignCenter)
+		renderer(row, 6, endFormatted, 0, tview.AlignCenter)
+		renderer(row, 7, util.HumanizeDuration(task.Duration), 0, tview.AlignRight)
+		renderer(row, 8, task.ReportedIcon(), 0, tview.AlignCenter)
+	}
+}
+
+func formatTags(tags []string) string {
+	var result []string
+	for _, tag := range tags {
+		result = append(result, "[gray]#"+tview.Escape(tag)+"[-]")
 	}
+	return strings.Join(result, " ")
 }
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var issueStatusOptions = []string{"open", "closed", "all"}
+
+type IssuesState struct {
+	Issues         []types.Issue
+	Total          int
+	Filter         types.IssueFilter
+	Table          *components.Table
+	ActionsLock    bool
+	ActionsManager *ActionsManager
+	Ctx            context.Context
+	Cancel         context.CancelFunc
+}
+
+func IssuesView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
+	state := &IssuesState{
+		Filter:      types.IssueFilter{Status: "open", Limit: types.ISSUES_PAGE_SIZE},
+		ActionsLock: true,
+	}
+	state.Ctx, state.Cancel = context.WithCancel(context.Background())
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, issuesViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Ext.ID", "Project", "Description", "Status"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+	state.Table.SetTitle("My Issues")
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Table.GetTable(), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	loadIssues(app, deps, state)
+
+	return layout
+}
+
+func loadIssues(app *tview.Application, deps *Dependencies, state *IssuesState) {
+	state.ActionsLock = true
+	state.ActionsManager.Refresh()
+	state.Table.SetTitle("My Issues (loading...)")
+
+	go func() {
+		issues, total, err := deps.Integration.ListMyIssues(state.Ctx, state.Filter)
+
+		app.QueueUpdateDraw(func() {
+			state.ActionsLock = false
+
+			if err != nil {
+				log.Println("Error loading issues:", err)
+				state.Issues = nil
+				state.Total = 0
+				renderIssuesTable(state)
+				state.Table.SetTitle(fmt.Sprintf("My Issues ([red]%s[-])", errorLabel(err)))
+				state.ActionsManager.Refresh()
+				return
+			}
+
+			state.Issues = issues
+			state.Total = total
+			renderIssuesTable(state)
+
+			first := state.Filter.Offset + 1
+			if len(issues) == 0 {
+				first = 0
+			}
+			state.Table.SetTitle(fmt.Sprintf("My Issues (%d-%d of %d)", first, state.Filter.Offset+len(issues), total))
+			state.ActionsManager.Refresh()
+		})
+	}()
+}
+
+func renderIssuesTable(state *IssuesState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, issue := range state.Issues {
+		row := row + 1
+		renderer(row, 0, issue.ExternalId, 0, tview.AlignLeft)
+		renderer(row, 1, tview.Escape(issue.Project), 0, tview.AlignLeft)
+		renderer(row, 2, tview.Escape(issue.Subject), 1, tview.AlignLeft)
+		renderer(row, 3, tview.Escape(issue.Status), 0, tview.AlignLeft)
+	}
+}
+
+func issuesViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *IssuesState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("issues").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		},
+	)
+
+	nextIssue := GetNewAction("Next Issue", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Issues) > 0 },
+		func() {},
+	)
+
+	prevIssue := GetNewAction("Prev Issue", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Issues) > 0 },
+		func() {},
+	)
+
+	prevPage := GetNewAction("Prev Page", NewRuneKey("h", 'h'),
+		func() bool { return !state.ActionsLock && state.Filter.Offset > 0 },
+		func() {
+			state.Filter.Offset = max(0, state.Filter.Offset-state.Filter.Limit)
+			loadIssues(app, deps, state)
+		},
+	)
+
+	nextPage := GetNewAction("Next Page", NewRuneKey("l", 'l'),
+		func() bool { return !state.ActionsLock && state.Filter.Offset+len(state.Issues) < state.Total },
+		func() {
+			state.Filter.Offset += state.Filter.Limit
+			loadIssues(app, deps, state)
+		},
+	)
+
+	filterAction := GetNewAction("Filter", NewRuneKey("f", 'f'),
+		func() bool { return !state.ActionsLock },
+		func() {
+			showIssuesFilterModal(app, pages, deps, state)
+		},
+	)
+
+	startAction := GetNewAction("Start Task", NewSpecialKey("Enter", tcell.KeyEnter),
+		func() bool {
+			_, err := getSelectedIssue(state)
+			return err == nil
+		},
+		func() {
+			issue, _ := getSelectedIssue(state)
+			showStartIssueModal(app, pages, deps, state, issue)
+		},
+	)
+
+	return &[]Action{closeAction, nextIssue, prevIssue, prevPage, nextPage, filterAction, startAction}
+}
+
+func getSelectedIssue(state *IssuesState) (types.Issue, error) {
+	row := state.Table.GetRowSelected()
+	if row == -1 {
+		return types.Issue{}, fmt.Errorf("no issue selected")
+	}
+	return state.Issues[row], nil
+}
+
+func showIssuesFilterModal(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *IssuesState) {
+	filter := state.Filter
+
+	currentStatus := 0
+	for i, option := range issueStatusOptions {
+		if option == filter.Status {
+			currentStatus = i
+		}
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Status: ", issueStatusOptions, currentStatus, func(option string, _ int) { filter.Status = option }).
+		AddInputField("Project: ", filter.Project, 0, nil, func(text string) { filter.Project = text }).
+		AddInputField("Text: ", filter.Text, 0, nil, func(text string) { filter.Text = text }).
+		AddInputField("Instance: ", filter.Instance, 0, nil, func(text string) { filter.Instance = text })
+
+	components.ShowFormModal("Filter Issues", 80, 13, form, pages, app, func() {
+		filter.Offset = 0
+		state.Filter = filter
+		loadIssues(app, deps, state)
+	}, nil)
+}
+
+func showStartIssueModal(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *IssuesState, issue types.Issue) {
+	description := issue.Subject
+	project := issue.Project
+	externalId := issue.ExternalId
+
+	form := tview.NewForm().
+		AddTextView("Issue: ", externalId, 0, 1, false, false).
+		AddInputField("Project: ", project, 0, nil, func(text string) { project = text }).
+		AddInputField("Description", description, 0, nil, func(text string) { description = text })
+
+	components.ShowFormModal("Start Task", 80, 11, form, pages, app, func() {
+		if description == "" {
+			components.ShowAlertModal(app, pages, "Description cannot be empty", nil)
+			return
+		}
+
+		confirmProject(app, pages, deps, &project, func() {
+			err := deps.Service.CreateTask(description, &project, &externalId, nil)
+			if err != nil {
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error creating task: %s", err.Error()), nil)
+				return
+			}
+
+			state.Cancel()
+			pages.
+				RemovePage("issues").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		})
+	}, nil)
+}
@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/service/integration"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/francescarpi/mytime/internal/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type OutboxState struct {
+	Entries        []model.OutboxEntry
+	Payloads       []types.OutboxPayload
+	Tasks          []types.TasksToSync
+	TaskErrors     []error
+	Table          *components.Table
+	ActionsLock    bool
+	ActionsManager *ActionsManager
+	Ctx            context.Context
+	Cancel         context.CancelFunc
+}
+
+func OutboxView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
+	state := &OutboxState{}
+	state.Ctx, state.Cancel = context.WithCancel(context.Background())
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, outboxViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Date", "Ext.ID", "Description", "Duration", "Integration", "Attempts", "Last Error"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Table.GetTable(), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	loadOutbox(deps, state, "")
+
+	return layout
+}
+
+func loadOutbox(deps *Dependencies, state *OutboxState, suffix string) {
+	entries, err := deps.Service.GetOutbox()
+	if err != nil {
+		log.Println("Error loading the outbox:", err)
+		suffix = fmt.Sprintf(" ([red]%s[-])", errorLabel(err))
+	}
+
+	state.Entries = entries
+	state.Payloads = make([]types.OutboxPayload, len(entries))
+	state.Tasks = make([]types.TasksToSync, len(entries))
+	state.TaskErrors = make([]error, len(entries))
+	for i, entry := range entries {
+		state.Payloads[i], err = deps.Service.OutboxPayload(entry)
+		if err != nil {
+			log.Println("Error decoding outbox entry:", entry.ID, err)
+			state.TaskErrors[i] = err
+			continue
+		}
+
+		// the group is shown as it would be sent now
+		state.Tasks[i], state.TaskErrors[i] = deps.Service.OutboxTask(state.Payloads[i])
+		if state.TaskErrors[i] == nil {
+			state.Tasks[i] = deps.Integration.Round(state.Tasks[i])
+		}
+	}
+
+	renderOutboxTable(state)
+	state.Table.SetTitle(fmt.Sprintf("Outbox (%d)%s", len(entries), suffix))
+	state.ActionsManager.Refresh()
+}
+
+func renderOutboxTable(state *OutboxState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, entry := range state.Entries {
+		task := state.Tasks[row]
+		desc := tview.Escape(task.Desc)
+		if err := state.TaskErrors[row]; err != nil {
+			desc = "[red]" + tview.Escape(err.Error())
+		}
+		row := row + 1
+
+		lastError := "[yellow]Deferred"
+		if entry.LastError != "" {
+			lastError = "[red]" + tview.Escape(entry.LastError)
+		}
+
+		renderer(row, 0, task.Date, 0, tview.AlignLeft)
+		renderer(row, 1, task.ExternalId, 0, tview.AlignLeft)
+		renderer(row, 2, desc, 1, tview.AlignLeft)
+		renderer(row, 3, util.HumanizeDuration(task.Duration), 0, tview.AlignRight)
+		renderer(row, 4, entry.Integration, 0, tview.AlignLeft)
+		renderer(row, 5, strconv.Itoa(entry.Attempts), 0, tview.AlignRight)
+		renderer(row, 6, lastError, 1, tview.AlignLeft)
+	}
+}
+
+func outboxViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *OutboxState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("outbox").
+				AddPage("sync", SyncView(app, pages, deps), true, true)
+		},
+	)
+
+	inspectAction := GetNewAction("Inspect", NewSpecialKey("Enter", tcell.KeyEnter),
+		func() bool { return !state.ActionsLock && state.Table.GetRowSelected() > -1 },
+		func() {
+			handleInspectOutboxEntry(app, pages, state)
+		},
+	)
+
+	discardAction := GetNewAction("Discard", NewRuneKey("x", 'x'),
+		func() bool { return !state.ActionsLock && state.Table.GetRowSelected() > -1 },
+		func() {
+			handleDiscardOutboxEntry(app, pages, deps, state)
+		},
+	)
+
+	retryAction := GetNewAction("Retry All", NewRuneKey("r", 'r'),
+		func() bool { return !state.ActionsLock && len(state.Entries) > 0 },
+		func() {
+			handleRetryOutbox(app, deps, state)
+		},
+	)
+
+	nextEntry := GetNewAction("Next Entry", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Entries) > 0 },
+		func() {},
+	)
+
+	prevEntry := GetNewAction("Prev Entry", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Entries) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, nextEntry, prevEntry, inspectAction, discardAction, retryAction}
+}
+
+func handleInspectOutboxEntry(app *tview.Application, pages *tview.Pages, state *OutboxState) {
+	row := state.Table.GetRowSelected()
+	entry := state.Entries[row]
+
+	payload, err := json.MarshalIndent(state.Payloads[row], "", "  ")
+	if err != nil {
+		payload = []byte(entry.Payload)
+	}
+
+	current := "Current group: "
+	if err := state.TaskErrors[row]; err != nil {
+		current += err.Error()
+	} else if task, err := json.MarshalIndent(state.Tasks[row], "", "  "); err == nil {
+		current += string(task)
+	}
+
+	details := fmt.Sprintf("Integration: %s\nAttempts: %d\nCreated: %s\nUpdated: %s\n",
+		entry.Integration,
+		entry.Attempts,
+		entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		entry.UpdatedAt.Format("2006-01-02 15:04:05"),
+	)
+	if entry.LastError != "" {
+		details += "Last error: " + entry.LastError + "\n"
+	}
+	details += "\n" + string(payload) + "\n\n" + current
+
+	form := tview.NewForm().
+		AddTextView("", tview.Escape(details), 0, 20, true, true)
+
+	components.ShowFormModal("Outbox Entry", 100, 26, form, pages, app, nil, func() {
+		state.Table.Deselect()
+	})
+}
+
+func handleDiscardOutboxEntry(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *OutboxState) {
+	row := state.Table.GetRowSelected()
+	entry := state.Entries[row]
+
+	components.ShowConfirmModal(
+		app,
+		pages,
+		"confirmDiscard",
+		"Discard this entry? Its tasks will be listed again in the sync view.",
+		[]string{"Cancel", "Discard"},
+		func(button string) {
+			if button == "Discard" {
+				if err := deps.Service.DiscardOutboxEntry(entry); err != nil {
+					log.Println("Error discarding outbox entry:", err)
+				}
+				loadOutbox(deps, state, "")
+			}
+			state.Table.Deselect()
+		},
+	)
+}
+
+func handleRetryOutbox(app *tview.Application, deps *Dependencies, state *OutboxState) {
+	state.ActionsLock = true
+	state.ActionsManager.Refresh()
+	state.Table.SetTitle(fmt.Sprintf("Outbox (%d) (sending...)", len(state.Entries)))
+
+	entries := state.Entries
+
+	go func() {
+		results := deps.Integration.SendOutbox(state.Ctx, deps.Service, entries)
+		for _, result := range results {
+			if result.Err != nil {
+				log.Println("Error sending outbox entry:", result.Entry.ID, result.Err)
+			}
+		}
+		sent, failed, discarded := integration.CountOutboxResults(results)
+
+		app.QueueUpdateDraw(func() {
+			state.ActionsLock = false
+			loadOutbox(deps, state, fmt.Sprintf(" - %d sent, %d failed, %d discarded", sent, failed, discarded))
+		})
+	}()
+}
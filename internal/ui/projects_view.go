@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type ProjectsState struct {
+	Projects       []model.Project
+	Table          *components.Table
+	ActionsManager *ActionsManager
+}
+
+func ProjectsView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
+	state := &ProjectsState{}
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, projectsViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Name", "Client", "Rate", "Ext.ID", "Integration", "Activity", "Archived"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Table.GetTable(), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	loadProjects(deps, state)
+
+	return layout
+}
+
+func loadProjects(deps *Dependencies, state *ProjectsState) {
+	suffix := ""
+	projects, err := deps.Service.GetProjects()
+	if err != nil {
+		log.Println("Error loading projects:", err)
+		suffix = fmt.Sprintf(" ([red]%s[-])", errorLabel(err))
+	}
+
+	state.Projects = projects
+	renderProjectsTable(state)
+	state.Table.SetTitle(fmt.Sprintf("Projects (%d)%s", len(projects), suffix))
+	state.ActionsManager.Refresh()
+}
+
+func renderProjectsTable(state *ProjectsState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, project := range state.Projects {
+		row := row + 1
+
+		rate := ""
+		if project.HourlyRate != 0 {
+			rate = strconv.FormatFloat(project.HourlyRate, 'f', 2, 64)
+		}
+
+		activity := ""
+		if project.DefaultActivityId != 0 {
+			activity = strconv.Itoa(project.DefaultActivityId)
+		}
+
+		archived := ""
+		if project.Archived {
+			archived = "✅"
+		}
+
+		renderer(row, 0, project.Label(), 1, tview.AlignLeft)
+		renderer(row, 1, tview.Escape(project.Client), 0, tview.AlignLeft)
+		renderer(row, 2, rate, 0, tview.AlignRight)
+		renderer(row, 3, tview.Escape(project.DefaultExternalId), 0, tview.AlignLeft)
+		renderer(row, 4, project.DefaultIntegration, 0, tview.AlignLeft)
+		renderer(row, 5, activity, 0, tview.AlignRight)
+		renderer(row, 6, archived, 0, tview.AlignCenter)
+	}
+}
+
+func projectsViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *ProjectsState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			pages.
+				RemovePage("projects").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		},
+	)
+
+	newAction := GetNewAction("New", NewRuneKey("n", 'n'),
+		func() bool { return true },
+		func() {
+			showProjectModal(app, pages, deps, state, model.Project{})
+		},
+	)
+
+	editAction := GetNewAction("Edit", NewRuneKey("m", 'm'),
+		func() bool { return state.Table.GetRowSelected() > -1 },
+		func() {
+			showProjectModal(app, pages, deps, state, state.Projects[state.Table.GetRowSelected()])
+		},
+	)
+
+	archiveAction := GetNewAction("Archive/Unarchive", NewRuneKey("a", 'a'),
+		func() bool { return state.Table.GetRowSelected() > -1 },
+		func() {
+			project := state.Projects[state.Table.GetRowSelected()]
+			project.Archived = !project.Archived
+			if err := deps.Service.SaveProject(&project); err != nil {
+				log.Println("Error archiving project:", project.Name, err)
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error saving the project: %s", err.Error()), nil)
+			}
+			state.Table.Deselect()
+			loadProjects(deps, state)
+		},
+	)
+
+	nextProject := GetNewAction("Next Project", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Projects) > 0 },
+		func() {},
+	)
+
+	prevProject := GetNewAction("Prev Project", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Projects) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, nextProject, prevProject, newAction, editAction, archiveAction}
+}
+
+func showProjectModal(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *ProjectsState, project model.Project) {
+	rate := ""
+	if project.HourlyRate != 0 {
+		rate = strconv.FormatFloat(project.HourlyRate, 'f', -1, 64)
+	}
+
+	activity := ""
+	if project.DefaultActivityId != 0 {
+		activity = strconv.Itoa(project.DefaultActivityId)
+	}
+
+	form := tview.NewForm().
+		AddInputField("Name", project.Name, 0, nil, func(text string) { project.Name = text }).
+		AddInputField("Color", project.Color, 0, nil, func(text string) { project.Color = strings.TrimSpace(text) }).
+		AddInputField("Client", project.Client, 0, nil, func(text string) { project.Client = text }).
+		AddInputField("Hourly Rate", rate, 0, nil, func(text string) { rate = text }).
+		AddInputField("Default External Id", project.DefaultExternalId, 0, nil, func(text string) { project.DefaultExternalId = text }).
+		AddInputField("Default Integration", project.DefaultIntegration, 0, nil, func(text string) { project.DefaultIntegration = text }).
+		AddInputField("Default Activity Id", activity, 0, nil, func(text string) { activity = text })
+
+	title := "New Project"
+	if project.ID != 0 {
+		title = "Edit Project"
+	}
+
+	state.Table.SetDisableAutomaticDeselect(true)
+	components.ShowFormModal(title, 80, 22, form, pages, app, func() {
+		if strings.TrimSpace(project.Name) == "" {
+			components.ShowAlertModal(app, pages, "Name cannot be empty", nil)
+			return
+		}
+
+		var err error
+		project.HourlyRate, project.DefaultActivityId = 0, 0
+		if rate != "" {
+			if project.HourlyRate, err = strconv.ParseFloat(rate, 64); err != nil {
+				components.ShowAlertModal(app, pages, "The hourly rate has to be a number", nil)
+				return
+			}
+		}
+		if activity != "" {
+			if project.DefaultActivityId, err = strconv.Atoi(activity); err != nil {
+				components.ShowAlertModal(app, pages, "The default activity has to be a number", nil)
+				return
+			}
+		}
+
+		if err := deps.Service.SaveProject(&project); err != nil {
+			components.ShowAlertModal(app, pages, fmt.Sprintf("Error saving the project: %s", err.Error()), nil)
+			return
+		}
+		loadProjects(deps, state)
+	}, func() {
+		state.Table.SetDisableAutomaticDeselect(false)
+		state.Table.Deselect()
+	})
+}
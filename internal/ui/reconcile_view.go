@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/francescarpi/mytime/internal/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type ReconcileState struct {
+	From           time.Time
+	To             time.Time
+	Rows           []service.ReconcileRow
+	Table          *components.Table
+	ActionsLock    bool
+	ActionsManager *ActionsManager
+	Ctx            context.Context
+	Cancel         context.CancelFunc
+}
+
+func ReconcileView(app *tview.Application, pages *tview.Pages, deps *Dependencies, date time.Time) tview.Primitive {
+	state := &ReconcileState{ActionsLock: true}
+	state.From, state.To = util.WeekBounds(date)
+	state.Ctx, state.Cancel = context.WithCancel(context.Background())
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, reconcileViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Date", "Integration", "Ext.ID", "Local", "Reported", "Remote", "Status"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Table.GetTable(), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	loadReconciliation(app, deps, state)
+
+	return layout
+}
+
+func loadReconciliation(app *tview.Application, deps *Dependencies, state *ReconcileState) {
+	state.ActionsLock = true
+	state.ActionsManager.Refresh()
+	state.Table.SetTitle(fmt.Sprintf("Reconciliation %s - %s (loading...)", state.From.Format(time.DateOnly), state.To.Format(time.DateOnly)))
+
+	go func() {
+		rows, err := deps.Integration.Reconcile(state.Ctx, deps.Service, state.From, state.To)
+
+		app.QueueUpdateDraw(func() {
+			state.ActionsLock = false
+			title := fmt.Sprintf("Reconciliation %s - %s", state.From.Format(time.DateOnly), state.To.Format(time.DateOnly))
+
+			if err != nil {
+				log.Println("Error reconciling tasks:", err)
+				rows = nil
+				title += fmt.Sprintf(" ([red]%s[-])", errorLabel(err))
+			}
+
+			state.Rows = rows
+			renderReconcileTable(state)
+			state.Table.SetTitle(title)
+			state.ActionsManager.Refresh()
+		})
+	}()
+}
+
+func renderReconcileTable(state *ReconcileState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, r := range state.Rows {
+		row := row + 1
+
+		color := "red"
+		if r.Status == service.ReconcileOk {
+			color = "green"
+		} else if r.Status == service.ReconcileMissingRemote {
+			color = "yellow"
+		}
+
+		renderer(row, 0, r.Date, 0, tview.AlignLeft)
+		renderer(row, 1, r.Integration, 0, tview.AlignLeft)
+		renderer(row, 2, r.ExternalId, 0, tview.AlignLeft)
+		renderer(row, 3, util.HumanizeDuration(r.Local), 0, tview.AlignRight)
+		renderer(row, 4, util.HumanizeDuration(r.Reported), 0, tview.AlignRight)
+		renderer(row, 5, util.HumanizeDuration(r.Remote), 0, tview.AlignRight)
+		renderer(row, 6, fmt.Sprintf("[%s]%s", color, r.Status), 0, tview.AlignLeft)
+	}
+}
+
+func reconcileViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *ReconcileState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("reconcile").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		},
+	)
+
+	prevWeek := GetNewAction("Prev Week", NewRuneKey("h", 'h'),
+		func() bool { return !state.ActionsLock },
+		func() {
+			state.From = state.From.AddDate(0, 0, -7)
+			state.To = state.To.AddDate(0, 0, -7)
+			loadReconciliation(app, deps, state)
+		},
+	)
+
+	nextWeek := GetNewAction("Next Week", NewRuneKey("l", 'l'),
+		func() bool { return !state.ActionsLock && state.To.Before(time.Now()) },
+		func() {
+			state.From = state.From.AddDate(0, 0, 7)
+			state.To = state.To.AddDate(0, 0, 7)
+			loadReconciliation(app, deps, state)
+		},
+	)
+
+	nextRow := GetNewAction("Next Row", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Rows) > 0 },
+		func() {},
+	)
+
+	prevRow := GetNewAction("Prev Row", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Rows) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, prevWeek, nextWeek, nextRow, prevRow}
+}
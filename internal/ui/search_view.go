@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/service"
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/francescarpi/mytime/internal/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type SearchState struct {
+	Query          string
+	Days           []service.SearchDay
+	Input          *tview.InputField
+	Table          *components.Table
+	ActionsManager *ActionsManager
+}
+
+func SearchView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
+	state := &SearchState{}
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, searchViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Date", "Project", "Description", "Ext.ID", "Duration"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	state.Input = tview.NewInputField().
+		SetLabel("Search: ").
+		SetFieldBackgroundColor(tcell.ColorGray)
+	state.Input.SetBorder(true)
+	state.Input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			state.Query = state.Input.GetText()
+			loadSearch(deps, state)
+		}
+		app.SetFocus(state.Table.GetTable())
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Input, 3, 0, true).
+		AddItem(state.Table.GetTable(), 0, 1, false).
+		AddItem(footer, 3, 0, false)
+
+	loadSearch(deps, state)
+
+	return layout
+}
+
+func loadSearch(deps *Dependencies, state *SearchState) {
+	suffix := ""
+	days, err := deps.Service.SearchTasks(state.Query, types.DateRange{}, 0)
+	if err != nil {
+		log.Println("Error searching tasks:", err)
+		suffix = fmt.Sprintf(" ([red]%s[-])", errorLabel(err))
+	}
+
+	state.Days = days
+	renderSearchTable(state)
+
+	found := 0
+	for _, day := range days {
+		found += len(day.Tasks)
+	}
+
+	title := "Search"
+	if strings.TrimSpace(state.Query) != "" {
+		title = fmt.Sprintf("Search \"%s\" (%d tasks in %d days)", tview.Escape(state.Query), found, len(days))
+	}
+	state.Table.SetTitle(title + suffix)
+	state.ActionsManager.Refresh()
+}
+
+func renderSearchTable(state *SearchState) {
+	renderer := state.Table.GetRowRenderer()
+
+	row := 1
+	for _, day := range state.Days {
+		renderer(row, 0, "[yellow]"+day.Date+"[-]", 0, tview.AlignLeft)
+		renderer(row, 1, "", 0, tview.AlignLeft)
+		renderer(row, 2, fmt.Sprintf("[yellow]%d tasks[-]", len(day.Tasks)), 1, tview.AlignLeft)
+		renderer(row, 3, "", 0, tview.AlignLeft)
+		renderer(row, 4, "[yellow]"+util.HumanizeDuration(day.Duration)+"[-]", 0, tview.AlignRight)
+		row++
+
+		for _, task := range day.Tasks {
+			externalId := ""
+			if task.ExternalId != nil {
+				externalId = *task.ExternalId
+			}
+
+			renderer(row, 0, "  "+task.Start.Format("15:04"), 0, tview.AlignLeft)
+			renderer(row, 1, tview.Escape(task.ProjectName()), 0, tview.AlignLeft)
+			renderer(row, 2, tview.Escape(task.Desc), 1, tview.AlignLeft)
+			renderer(row, 3, tview.Escape(externalId), 0, tview.AlignLeft)
+			renderer(row, 4, util.HumanizeDuration(task.Duration), 0, tview.AlignRight)
+			row++
+		}
+	}
+}
+
+func searchViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *SearchState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			pages.
+				RemovePage("search").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		},
+	)
+
+	searchAction := GetNewAction("Search", NewRuneKey("/", '/'),
+		func() bool { return true },
+		func() {
+			state.Table.Deselect()
+			app.SetFocus(state.Input)
+		},
+	)
+
+	nextRow := GetNewAction("Next Row", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Days) > 0 },
+		func() {},
+	)
+
+	prevRow := GetNewAction("Prev Row", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Days) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, searchAction, nextRow, prevRow}
+}
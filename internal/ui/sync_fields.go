@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/rivo/tview"
+)
+
+func customFieldValues(fields []types.CustomField) []types.CustomFieldValue {
+	var values []types.CustomFieldValue
+	for _, field := range fields {
+		if field.Value != "" {
+			values = append(values, types.CustomFieldValue{Id: field.Id, Value: field.Value})
+		}
+	}
+	return values
+}
+
+func missingCustomFields(fields []types.CustomField) bool {
+	return slices.ContainsFunc(fields, func(field types.CustomField) bool {
+		return field.Required && field.Value == ""
+	})
+}
+
+func customFieldsLabel(fields []types.CustomField) string {
+	if missingCustomFields(fields) {
+		return "[red]Fill fields!"
+	}
+
+	var values []string
+	for _, field := range fields {
+		if field.Value != "" {
+			values = append(values, fmt.Sprintf("%s=%s", field.Name, field.Value))
+		}
+	}
+	return tview.Escape(strings.Join(values, ", "))
+}
+
+func handleEditCustomFields(app *tview.Application, pages *tview.Pages, state *SyncState) {
+	task, taskRow, err := getSelectedTaskToSync(state)
+	if err != nil {
+		return
+	}
+
+	fields := state.TasksActivities[taskRow].CustomFields
+
+	form := tview.NewForm().
+		AddTextView("Task: ", task.Desc, 0, 1, false, false)
+
+	getters := make([]func() string, len(fields))
+	for i, field := range fields {
+		label := field.Name + ": "
+		if field.Required {
+			label = field.Name + " *: "
+		}
+
+		if len(field.PossibleValues) == 0 {
+			input := tview.NewInputField().SetLabel(label).SetText(field.Value)
+			form.AddFormItem(input)
+			getters[i] = input.GetText
+			continue
+		}
+
+		options := field.PossibleValues
+		if !field.Required {
+			options = append([]string{""}, options...)
+		}
+
+		dropdown := newSyncDropDown(label, options, slices.Index(options, field.Value))
+		form.AddFormItem(dropdown)
+		getters[i] = func() string {
+			_, value := dropdown.GetCurrentOption()
+			return value
+		}
+	}
+
+	components.ShowFormModal("Custom Fields", 80, 7+2*len(fields), form, pages, app, func() {
+		for i := range fields {
+			fields[i].Value = getters[i]()
+		}
+
+		state.Tasks[taskRow].CustomFields = customFieldValues(fields)
+		state.Table.SetCellText(taskRow+1, SYNC_COL_FIELDS, customFieldsLabel(fields))
+		state.checkAllTasksHaveFields()
+		state.Table.Deselect()
+	}, nil)
+}
+
+func (s *SyncState) checkAllTasksHaveFields() {
+	s.AllTasksHaveFields = !slices.ContainsFunc(s.TasksActivities, func(task TaskToSyncActivities) bool {
+		return missingCustomFields(task.CustomFields)
+	})
+	s.ActionsManager.Refresh()
+}
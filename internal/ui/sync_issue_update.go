@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/francescarpi/mytime/internal/types"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var doneRatioOptions = []string{"0", "10", "20", "30", "40", "50", "60", "70", "80", "90", "100"}
+
+// handleUpdateIssue lets the user move the issue of a synced row to another
+// status, change its % done or add a note to it.
+func handleUpdateIssue(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *SyncState) {
+	task, taskRow, err := getSelectedTaskToSync(state)
+	if err != nil {
+		return
+	}
+	row := taskRow + 1
+
+	state.Table.SetCellText(row, SYNC_COL_STATUS, "⏳")
+
+	go func() {
+		statuses, current, doneRatio, err := deps.Integration.LoadIssueStatuses(state.Ctx, task)
+
+		app.QueueUpdateDraw(func() {
+			if err != nil {
+				log.Println("Error loading issue statuses:", err)
+				state.Table.SetCellText(row, SYNC_COL_STATUS, "🟢 ⚠️ [red]"+errorLabel(err))
+				return
+			}
+
+			state.Table.SetCellText(row, SYNC_COL_STATUS, "🟢")
+			showUpdateIssueModal(app, pages, deps, state, task, row, statuses, *current, doneRatio)
+		})
+	}()
+}
+
+func showUpdateIssueModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	deps *Dependencies,
+	state *SyncState,
+	task types.TasksToSync,
+	row int,
+	statuses []types.IssueStatus,
+	current types.IssueStatus,
+	doneRatio int,
+) {
+	if !slices.ContainsFunc(statuses, func(status types.IssueStatus) bool { return status.Id == current.Id }) {
+		statuses = append([]types.IssueStatus{current}, statuses...)
+	}
+
+	statusOptions := []string{}
+	currentStatus := 0
+	for i, status := range statuses {
+		statusOptions = append(statusOptions, status.Name)
+		if status.Id == current.Id {
+			currentStatus = i
+		}
+	}
+
+	currentRatio := slices.Index(doneRatioOptions, strconv.Itoa(doneRatio/10*10))
+
+	statusDropdown := newSyncDropDown("Status: ", statusOptions, currentStatus)
+	ratioDropdown := newSyncDropDown("% Done: ", doneRatioOptions, currentRatio)
+
+	form := tview.NewForm().
+		AddTextView("Issue: ", task.ExternalId, 0, 1, false, false).
+		AddFormItem(statusDropdown).
+		AddFormItem(ratioDropdown).
+		AddInputField("Notes: ", "", 0, nil, nil)
+
+	components.ShowFormModal("Update Issue", 80, 13, form, pages, app, func() {
+		var update types.IssueUpdate
+
+		statusIdx, _ := statusDropdown.GetCurrentOption()
+		if statuses[statusIdx].Id != current.Id {
+			update.StatusId = statuses[statusIdx].Id
+		}
+
+		ratioIdx, _ := ratioDropdown.GetCurrentOption()
+		if ratioIdx != currentRatio {
+			ratio, _ := strconv.Atoi(doneRatioOptions[ratioIdx])
+			update.DoneRatio = &ratio
+		}
+
+		update.Notes = form.GetFormItemByLabel("Notes: ").(*tview.InputField).GetText()
+
+		if update.StatusId == 0 && update.DoneRatio == nil && update.Notes == "" {
+			state.Table.Deselect()
+			return
+		}
+
+		state.Table.SetCellText(row, SYNC_COL_STATUS, "⏳")
+		go func() {
+			err := deps.Integration.UpdateIssue(state.Ctx, task, update)
+
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					log.Println("Error updating issue:", task.ExternalId, err)
+					state.Table.SetCellText(row, SYNC_COL_STATUS, "🟢 ⚠️ [red]"+errorLabel(err))
+					return
+				}
+				state.Table.SetCellText(row, SYNC_COL_STATUS, "🟢 [green]Issue updated")
+			})
+		}()
+		state.Table.Deselect()
+	}, nil)
+}
+
+func newSyncDropDown(label string, options []string, current int) *tview.DropDown {
+	return tview.NewDropDown().
+		SetLabel(label).
+		SetOptions(options, nil).
+		SetCurrentOption(current).
+		SetListStyles(
+			tcell.StyleDefault.Background(tcell.ColorGray).Foreground(tcell.ColorWhite),
+			tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack),
+		).
+		SetFocusedStyle(
+			tcell.StyleDefault.Background(tcell.ColorGray).Foreground(tcell.ColorWhite),
+		)
+}
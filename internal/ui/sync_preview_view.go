@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/francescarpi/mytime/internal/service/integration"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/francescarpi/mytime/internal/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type SyncPreviewState struct {
+	Preview        integration.SyncPreview
+	Table          *components.Table
+	ActionsManager *ActionsManager
+}
+
+// SyncPreviewView shows the requests the sync would send. It is added on top
+// of the sync view, which is shown again when the preview is closed.
+func SyncPreviewView(app *tview.Application, pages *tview.Pages, deps *Dependencies, syncState *SyncState) tview.Primitive {
+	var items []integration.PreviewItem
+	for i, task := range syncState.Tasks {
+		if syncState.Deferred[i] {
+			continue
+		}
+		items = append(items, integration.PreviewItem{Task: task, Activity: *syncState.TasksActivities[i].Default})
+	}
+
+	state := &SyncPreviewState{Preview: deps.Integration.Preview(items)}
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, syncPreviewActions(app, pages, deps, state, syncState))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Integration", "Date", "Ext.ID", "Activity", "Request", "Payload"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+	state.Table.SetTitle(fmt.Sprintf("Sync Preview (%d entries)", len(state.Preview.Entries)))
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	totals := tview.NewTextView().SetDynamicColors(true).SetText(renderPreviewTotals(state.Preview))
+	totals.SetTitle("Totals").SetBorder(true)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(state.Table.GetTable(), 0, 1, true).
+			AddItem(totals, 32, 0, false), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	renderSyncPreviewTable(state)
+
+	return layout
+}
+
+func renderSyncPreviewTable(state *SyncPreviewState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, entry := range state.Preview.Entries {
+		row := row + 1
+
+		request := fmt.Sprintf("%s %s", entry.Method, entry.Url)
+		payload := tview.Escape(string(entry.Body))
+		if entry.Error != "" {
+			request = ""
+			payload = "[red]" + tview.Escape(entry.Error)
+		}
+
+		renderer(row, 0, entry.Integration, 0, tview.AlignLeft)
+		renderer(row, 1, entry.Date, 0, tview.AlignLeft)
+		renderer(row, 2, entry.ExternalId, 0, tview.AlignLeft)
+		renderer(row, 3, entry.Activity, 0, tview.AlignLeft)
+		renderer(row, 4, tview.Escape(request), 0, tview.AlignLeft)
+		renderer(row, 5, payload, 1, tview.AlignLeft)
+	}
+}
+
+func renderPreviewTotals(preview integration.SyncPreview) string {
+	var text strings.Builder
+
+	text.WriteString("[yellow]Per day[-]\n")
+	for _, total := range preview.TotalsByDay {
+		fmt.Fprintf(&text, "%s %8s %6.2f\n", total.Key, util.HumanizeDuration(total.Duration), total.Hours)
+	}
+
+	text.WriteString("\n[yellow]Per issue[-]\n")
+	for _, total := range preview.TotalsByIssue {
+		fmt.Fprintf(&text, "%-10s %8s %6.2f\n", tview.Escape(total.Key), util.HumanizeDuration(total.Duration), total.Hours)
+	}
+
+	return text.String()
+}
+
+func syncPreviewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *SyncPreviewState, syncState *SyncState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			pages.RemovePage("syncPreview")
+		},
+	)
+
+	syncAction := GetNewAction("Sync", NewRuneKey("s", 's'),
+		func() bool { return true },
+		func() {
+			pages.RemovePage("syncPreview")
+			handleSyncTasks(app, pages, syncState, deps)
+		},
+	)
+
+	exportAction := GetNewAction("Export JSON", NewRuneKey("e", 'e'),
+		func() bool { return len(state.Preview.Entries) > 0 },
+		func() {
+			handleExportPreview(app, pages, state)
+		},
+	)
+
+	nextEntry := GetNewAction("Next Entry", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Preview.Entries) > 0 },
+		func() {},
+	)
+
+	prevEntry := GetNewAction("Prev Entry", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Preview.Entries) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, nextEntry, prevEntry, syncAction, exportAction}
+}
+
+func handleExportPreview(app *tview.Application, pages *tview.Pages, state *SyncPreviewState) {
+	path := fmt.Sprintf("mytime-sync-preview-%s.json", time.Now().Format("20060102-150405"))
+
+	message := "Preview exported to " + path
+	content, err := json.MarshalIndent(state.Preview, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, content, 0644)
+	}
+	if err != nil {
+		log.Println("Error exporting the sync preview:", err)
+		message = "Error exporting the preview: " + err.Error()
+	}
+
+	components.ShowAlertModal(app, pages, message, nil)
+}
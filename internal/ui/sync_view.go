@@ -1,13 +1,13 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/francescarpi/mytime/internal/service/redmine"
+	"github.com/francescarpi/mytime/internal/service/integration"
 	"github.com/francescarpi/mytime/internal/types"
 	"github.com/francescarpi/mytime/internal/ui/components"
 	"github.com/francescarpi/mytime/internal/util"
@@ -15,18 +15,33 @@ import (
 	"github.com/rivo/tview"
 )
 
+const (
+	SYNC_COL_INTEGRATION = 6
+	SYNC_COL_ACTIVITY    = 7
+	SYNC_COL_FIELDS      = 8
+	SYNC_COL_STATUS      = 9
+)
+
 type TaskToSyncActivities struct {
-	Activities *[]redmine.RedmineProjectActivity
-	Default    *redmine.RedmineProjectActivity
-	Index      int
+	Activities   *[]types.Activity
+	Default      *types.Activity
+	CustomFields []types.CustomField
+	Index        int
 }
 
 type SyncState struct {
 	Tasks                []types.TasksToSync
 	Table                *components.Table
 	AllTasksHaveActivity bool
+	AllTasksHaveFields   bool
 	ActionsLock          bool
+	Loading              bool
+	Ctx                  context.Context
+	Cancel               context.CancelFunc
 	TasksActivities      []TaskToSyncActivities
+	Deferred             map[int]bool
+	Synced               map[int]bool
+	Queued               map[int]bool
 	ActionsManager       *ActionsManager
 }
 
@@ -35,7 +50,12 @@ func SyncView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 		Tasks:                deps.Service.GetTasksToSync(),
 		AllTasksHaveActivity: false,
 		ActionsLock:          true,
+		Loading:              true,
+		Deferred:             map[int]bool{},
+		Synced:               map[int]bool{},
+		Queued:               map[int]bool{},
 	}
+	state.Ctx, state.Cancel = context.WithCancel(context.Background())
 
 	state.TasksActivities = make([]TaskToSyncActivities, len(state.Tasks))
 
@@ -46,12 +66,12 @@ func SyncView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 
 	state.Table = components.GetNewTable(
 		app,
-		[]string{"Description", "Date", "Duration", "Ext.ID", "Tasks Ids", "Activity", "Status"},
+		[]string{"Description", "Date", "Duration", "Rounded", "Ext.ID", "Tasks Ids", "Integration", "Activity", "Fields", "Status"},
 		func() {
 			app.QueueUpdateDraw(state.ActionsManager.Refresh)
 		},
 	)
-	state.Table.SetTitle("Tasks Synchronization")
+	setSyncTitle(deps, state, "")
 
 	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
 
@@ -59,32 +79,58 @@ func SyncView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tv
 		AddItem(state.Table.GetTable(), 0, 1, true).
 		AddItem(footer, 3, 0, false)
 
-	renderSyncTable(state)
+	renderSyncTable(deps, state)
 	loadTasksActivity(app, deps, state)
 
 	return layout
 }
 
-func renderSyncTable(state *SyncState) {
+func setSyncTitle(deps *Dependencies, state *SyncState, suffix string) {
+	title := "Tasks Synchronization"
+
+	entries, err := deps.Service.GetOutbox()
+	if err != nil {
+		log.Println("Error loading the outbox:", err)
+	} else if len(entries) > 0 {
+		title += fmt.Sprintf(" (%d in outbox)", len(entries))
+	}
+
+	state.Table.SetTitle(title + suffix)
+}
+
+func renderSyncTable(deps *Dependencies, state *SyncState) {
 	renderer := state.Table.GetRowRenderer()
 
 	for row, task := range state.Tasks {
 		row := row + 1
 		renderer(row, 0, task.Desc, 1, tview.AlignLeft)
 		renderer(row, 1, task.Date, 0, tview.AlignLeft)
-		renderer(row, 2, util.HumanizeDuration(task.Duration), 0, tview.AlignRight)
-		renderer(row, 3, task.ExternalId, 0, tview.AlignLeft)
-		renderer(row, 4, strings.Join(task.Ids.IDs, ","), 0, tview.AlignRight)
-		renderer(row, 5, "[red]Loading...", 0, tview.AlignLeft)
-		renderer(row, 6, "🔴", 0, tview.AlignCenter)
+		renderer(row, 2, util.HumanizeDuration(task.RawDuration), 0, tview.AlignRight)
+		renderer(row, 3, roundedLabel(task), 0, tview.AlignRight)
+		renderer(row, 4, task.ExternalId, 0, tview.AlignLeft)
+		renderer(row, 5, strings.Join(task.Ids.IDs, ","), 0, tview.AlignRight)
+		integrationName, _ := deps.Integration.Resolve(task)
+		renderer(row, SYNC_COL_INTEGRATION, integrationName, 0, tview.AlignLeft)
+		renderer(row, SYNC_COL_ACTIVITY, "[red]Loading...", 0, tview.AlignLeft)
+		renderer(row, SYNC_COL_FIELDS, "", 0, tview.AlignLeft)
+		renderer(row, SYNC_COL_STATUS, "🔴", 0, tview.AlignCenter)
 	}
 
 }
 
+func roundedLabel(task types.TasksToSync) string {
+	rounded := util.HumanizeDuration(task.Duration)
+	if task.Duration != task.RawDuration {
+		return "[yellow]" + rounded
+	}
+	return rounded
+}
+
 func syncViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *SyncState) *[]Action {
 	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
-		func() bool { return !state.ActionsLock },
+		func() bool { return !state.ActionsLock || state.Loading },
 		func() {
+			state.Cancel()
 			pages.
 				RemovePage("sync").
 				AddPage("home", HomeView(app, pages, deps), true, true)
@@ -92,9 +138,9 @@ func syncViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 	)
 
 	syncAction := GetNewAction("Sync", NewRuneKey("s", 's'),
-		func() bool { return !state.ActionsLock && state.AllTasksHaveActivity },
+		func() bool { return !state.ActionsLock && state.AllTasksHaveActivity && state.AllTasksHaveFields },
 		func() {
-			handleSyncTasks(app, pages, state, deps)
+			pages.AddPage("syncPreview", SyncPreviewView(app, pages, deps, state), true, true)
 		},
 	)
 
@@ -108,6 +154,66 @@ func syncViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 		},
 	)
 
+	refreshAction := GetNewAction("Refresh", NewRuneKey("r", 'r'),
+		func() bool { return !state.ActionsLock && len(state.Tasks) > 0 },
+		func() {
+			deps.Integration.Refresh()
+			state.ActionsLock = true
+			state.Loading = true
+			state.AllTasksHaveActivity = false
+			state.Tasks = deps.Service.GetTasksToSync()
+			state.TasksActivities = make([]TaskToSyncActivities, len(state.Tasks))
+			state.Deferred = map[int]bool{}
+			state.Synced = map[int]bool{}
+			state.Queued = map[int]bool{}
+			renderSyncTable(deps, state)
+			setSyncTitle(deps, state, "")
+			state.ActionsManager.Refresh()
+			loadTasksActivity(app, deps, state)
+		},
+	)
+
+	deferAction := GetNewAction("Defer", NewRuneKey("d", 'd'),
+		func() bool {
+			row := state.Table.GetRowSelected()
+			return !state.ActionsLock && row > -1 && !state.Deferred[row] &&
+				state.TasksActivities[row].Default != nil && state.TasksActivities[row].Default.Name != ""
+		},
+		func() {
+			handleDeferTask(deps, state)
+		},
+	)
+
+	outboxAction := GetNewAction("Outbox", NewRuneKey("o", 'o'),
+		func() bool { return !state.ActionsLock },
+		func() {
+			state.Cancel()
+			pages.
+				RemovePage("sync").
+				AddPage("outbox", OutboxView(app, pages, deps), true, true)
+		},
+	)
+
+	fieldsAction := GetNewAction("Custom Fields", NewRuneKey("f", 'f'),
+		func() bool {
+			row := state.Table.GetRowSelected()
+			return !state.ActionsLock && row > -1 && len(state.TasksActivities[row].CustomFields) > 0
+		},
+		func() {
+			handleEditCustomFields(app, pages, state)
+		},
+	)
+
+	updateIssueAction := GetNewAction("Update Issue", NewRuneKey("u", 'u'),
+		func() bool {
+			row := state.Table.GetRowSelected()
+			return !state.ActionsLock && row > -1 && state.Synced[row] && deps.Integration.CanUpdateIssue(state.Tasks[row])
+		},
+		func() {
+			handleUpdateIssue(app, pages, deps, state)
+		},
+	)
+
 	nextTask := GetNewAction("Next Task", NewRuneKey("j", 'j'),
 		func() bool { return len(state.Tasks) > 0 },
 		func() {},
@@ -118,7 +224,7 @@ func syncViewActions(app *tview.Application, pages *tview.Pages, deps *Dependenc
 		func() {},
 	)
 
-	return &[]Action{closeAction, nextTask, prevTask, syncAction, selectAction}
+	return &[]Action{closeAction, nextTask, prevTask, syncAction, selectAction, fieldsAction, refreshAction, deferAction, outboxAction, updateIssueAction}
 }
 
 func loadTasksActivity(app *tview.Application, deps *Dependencies, state *SyncState) {
@@ -137,16 +243,25 @@ func loadTasksActivity(app *tview.Application, deps *Dependencies, state *SyncSt
 		close(resultsChan)
 
 		allTasksWithDefaultActivity := true
+		allTasksWithFields := true
 		for result := range resultsChan {
 			state.TasksActivities[result.Index] = result
+			state.Tasks[result.Index].CustomFields = customFieldValues(result.CustomFields)
 			if result.Default == nil || result.Default.Name == "" {
 				allTasksWithDefaultActivity = false
 			}
+			if missingCustomFields(result.CustomFields) {
+				allTasksWithFields = false
+			}
 		}
 
-		state.AllTasksHaveActivity = allTasksWithDefaultActivity
-		state.ActionsLock = false
-		app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		app.QueueUpdateDraw(func() {
+			state.AllTasksHaveActivity = allTasksWithDefaultActivity
+			state.AllTasksHaveFields = allTasksWithFields
+			state.ActionsLock = false
+			state.Loading = false
+			state.ActionsManager.Refresh()
+		})
 	}()
 }
 
@@ -162,26 +277,36 @@ func loadTaskActivity(
 	defer wg.Done()
 
 	log.Println("Loading task activity for externalId:", task.ExternalId)
-	activities, defaultActivity, err := deps.Redmine.LoadActivities(task.ExternalId)
+	activities, defaultActivity, err := deps.Integration.LoadActivities(state.Ctx, *task)
 	if err != nil {
 		log.Println("Error loading task activity:", err)
-		state.Table.SetCellText(row, 5, "[red]Connection Error!")
-		resultsChan <- TaskToSyncActivities{}
+		state.Table.SetCellText(row, SYNC_COL_ACTIVITY, "[red]"+errorLabel(err))
+		resultsChan <- TaskToSyncActivities{Index: row - 1}
+		return
+	}
+
+	customFields, err := deps.Integration.LoadCustomFields(state.Ctx, *task)
+	if err != nil {
+		log.Println("Error loading custom fields:", err)
+		state.Table.SetCellText(row, SYNC_COL_FIELDS, "[red]"+errorLabel(err))
+		resultsChan <- TaskToSyncActivities{Index: row - 1}
 		return
 	}
 
 	app.QueueUpdateDraw(func() {
 		if defaultActivity.Name == "" {
-			state.Table.SetCellText(row, 5, "[red]Select activity!")
+			state.Table.SetCellText(row, SYNC_COL_ACTIVITY, "[red]Select activity!")
 		} else {
-			state.Table.SetCellText(row, 5, "[green]"+defaultActivity.Name)
+			state.Table.SetCellText(row, SYNC_COL_ACTIVITY, "[green]"+defaultActivity.Name)
 		}
+		state.Table.SetCellText(row, SYNC_COL_FIELDS, customFieldsLabel(customFields))
 	})
 
 	resultsChan <- TaskToSyncActivities{
-		Activities: activities,
-		Default:    defaultActivity,
-		Index:      row - 1,
+		Activities:   activities,
+		Default:      defaultActivity,
+		CustomFields: customFields,
+		Index:        row - 1,
 	}
 }
 
@@ -199,9 +324,19 @@ func handleSyncTasks(app *tview.Application, pages *tview.Pages, state *SyncStat
 				state.ActionsLock = true
 				state.ActionsManager.Refresh()
 
+				outbox, err := deps.Service.GetOutbox()
+				if err != nil {
+					log.Println("Error loading the outbox:", err)
+				}
+
 				var wg sync.WaitGroup
 
+				// the rows synced or queued by a previous run are not sent
+				// again, the queued ones are retried from the outbox
 				for i, task := range state.Tasks {
+					if state.Deferred[i] || state.Synced[i] || state.Queued[i] {
+						continue
+					}
 					wg.Add(1)
 					go syncTask(&wg, app, &task, state.TasksActivities[i].Default.Id, i+1, state, deps)
 				}
@@ -211,8 +346,23 @@ func handleSyncTasks(app *tview.Application, pages *tview.Pages, state *SyncStat
 					wg.Wait()
 					log.Println("All goroutines finished")
 
-					state.ActionsLock = false
-					app.QueueUpdateDraw(state.ActionsManager.Refresh)
+					suffix := ""
+					if len(outbox) > 0 {
+						results := deps.Integration.SendOutbox(state.Ctx, deps.Service, outbox)
+						for _, result := range results {
+							if result.Err != nil {
+								log.Println("Error sending outbox entry:", result.Entry.ID, result.Err)
+							}
+						}
+						sent, failed, discarded := integration.CountOutboxResults(results)
+						suffix = fmt.Sprintf(" - outbox retried: %d sent, %d failed, %d discarded", sent, failed, discarded)
+					}
+
+					app.QueueUpdateDraw(func() {
+						state.ActionsLock = false
+						setSyncTitle(deps, state, suffix)
+						state.ActionsManager.Refresh()
+					})
 				}()
 			}
 		},
@@ -232,25 +382,43 @@ func syncTask(
 
 	log.Println("Syncing task:", task.Id, "with activityId:", activityId)
 	app.QueueUpdateDraw(func() {
-		state.Table.SetCellText(row, 6, "⏳")
+		state.Table.SetCellText(row, SYNC_COL_STATUS, "⏳")
 	})
 
-	err := deps.Redmine.SendTask(task.ExternalId, task.Desc, task.Date, task.Duration, activityId)
+	err := deps.Integration.Sync(state.Ctx, deps.Service, *task, activityId)
 	if err != nil {
+		log.Println("Error syncing task:", task.Id, err)
 		app.QueueUpdateDraw(func() {
-			state.Table.SetCellText(row, 6, "⚠️")
+			state.Queued[row-1] = true
+			state.Table.SetCellText(row, SYNC_COL_STATUS, "⚠️ [red]"+errorLabel(err)+" (outbox)")
 		})
 		return
 	}
 
 	app.QueueUpdateDraw(func() {
-		state.Table.SetCellText(row, 6, "🟢")
+		state.Synced[row-1] = true
+		state.Table.SetCellText(row, SYNC_COL_STATUS, "🟢")
+		state.ActionsManager.Refresh()
 	})
+}
 
-	for _, idStr := range task.Ids.IDs {
-		id, _ := strconv.Atoi(idStr)
-		deps.Service.SetTaskAsReported(uint(id))
+func handleDeferTask(deps *Dependencies, state *SyncState) {
+	task, taskRow, err := getSelectedTaskToSync(state)
+	if err != nil {
+		return
 	}
+
+	if err := deps.Integration.Defer(deps.Service, task, state.TasksActivities[taskRow].Default.Id); err != nil {
+		log.Println("Error deferring task:", err)
+		state.Table.SetCellText(taskRow+1, SYNC_COL_STATUS, "⚠️ [red]"+errorLabel(err))
+		return
+	}
+
+	state.Deferred[taskRow] = true
+	state.Table.SetCellText(taskRow+1, SYNC_COL_STATUS, "📥")
+	setSyncTitle(deps, state, "")
+	state.Table.Deselect()
+	state.ActionsManager.Refresh()
 }
 
 func getSelectedTaskToSync(state *SyncState) (types.TasksToSync, int, error) {
@@ -308,7 +476,7 @@ func handleSelectActivity(app *tview.Application, pages *tview.Pages, state *Syn
 		log.Println("Option selected", newActivity)
 		(*taskActivities.Default) = newActivity
 
-		state.Table.SetCellText(taskRow+1, 5, "[green]"+newActivity.Name)
+		state.Table.SetCellText(taskRow+1, SYNC_COL_ACTIVITY, "[green]"+newActivity.Name)
 		state.checkAllTasksHaveDefaultActivity(state)
 		state.Table.Deselect()
 	}, nil)
@@ -317,7 +485,7 @@ func handleSelectActivity(app *tview.Application, pages *tview.Pages, state *Syn
 func (s *SyncState) checkAllTasksHaveDefaultActivity(state *SyncState) {
 	haveDefault := true
 	for _, task := range s.TasksActivities {
-		if task.Default.Name == "" {
+		if task.Default == nil || task.Default.Name == "" {
 			haveDefault = false
 			break
 		}
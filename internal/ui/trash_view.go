@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/francescarpi/mytime/internal/model"
+	"github.com/francescarpi/mytime/internal/ui/components"
+	"github.com/francescarpi/mytime/internal/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type TrashState struct {
+	Entries        []model.TrashEntry
+	Tasks          []*model.Task
+	Table          *components.Table
+	ActionsManager *ActionsManager
+}
+
+func TrashView(app *tview.Application, pages *tview.Pages, deps *Dependencies) tview.Primitive {
+	state := &TrashState{}
+
+	footer := tview.NewTextView()
+	footer.SetDynamicColors(true).SetBorder(true)
+
+	state.ActionsManager = GetNewActionsManager(footer, trashViewActions(app, pages, deps, state))
+
+	state.Table = components.GetNewTable(
+		app,
+		[]string{"Deleted", "Date", "Project", "Description", "Ext.ID", "Duration", "Reported"},
+		func() {
+			app.QueueUpdateDraw(state.ActionsManager.Refresh)
+		},
+	)
+
+	state.Table.SetInputCapture(state.ActionsManager.GetInputHandler())
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.Table.GetTable(), 0, 1, true).
+		AddItem(footer, 3, 0, false)
+
+	loadTrash(deps, state, "")
+
+	return layout
+}
+
+func loadTrash(deps *Dependencies, state *TrashState, suffix string) {
+	entries, err := deps.Service.GetTrash()
+	if err != nil {
+		log.Println("Error loading the trash:", err)
+		suffix = fmt.Sprintf(" ([red]%s[-])", errorLabel(err))
+	}
+
+	state.Entries = entries
+	state.Tasks = make([]*model.Task, len(entries))
+	for i, entry := range entries {
+		state.Tasks[i], err = deps.Service.TrashedTask(entry)
+		if err != nil {
+			log.Println("Error decoding trash entry:", entry.ID, err)
+		}
+	}
+
+	renderTrashTable(state)
+	state.Table.SetTitle(fmt.Sprintf("Trash (%d)%s", len(entries), suffix))
+	state.ActionsManager.Refresh()
+}
+
+func renderTrashTable(state *TrashState) {
+	renderer := state.Table.GetRowRenderer()
+
+	for row, entry := range state.Entries {
+		task := state.Tasks[row]
+		row := row + 1
+
+		renderer(row, 0, entry.DeletedAt.Format("2006-01-02 15:04"), 0, tview.AlignLeft)
+		if task == nil {
+			renderer(row, 1, "[red]Unreadable task", 1, tview.AlignLeft)
+			continue
+		}
+
+		externalId := ""
+		if task.ExternalId != nil {
+			externalId = *task.ExternalId
+		}
+
+		duration := "-"
+		if task.End != nil {
+			duration = util.HumanizeDuration(int(task.End.Sub(task.Start.Time).Seconds()))
+		}
+
+		renderer(row, 1, task.Start.Format("2006-01-02 15:04"), 0, tview.AlignLeft)
+		renderer(row, 2, tview.Escape(task.ProjectName()), 0, tview.AlignLeft)
+		renderer(row, 3, tview.Escape(task.Desc), 1, tview.AlignLeft)
+		renderer(row, 4, externalId, 0, tview.AlignLeft)
+		renderer(row, 5, duration, 0, tview.AlignRight)
+		renderer(row, 6, task.ReportedIcon(), 0, tview.AlignCenter)
+	}
+}
+
+func trashViewActions(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *TrashState) *[]Action {
+	closeAction := GetNewAction("Close", NewSpecialKey("Esc", tcell.KeyEsc),
+		func() bool { return true },
+		func() {
+			pages.
+				RemovePage("trash").
+				AddPage("home", HomeView(app, pages, deps), true, true)
+		},
+	)
+
+	restoreAction := GetNewAction("Restore", NewRuneKey("r", 'r'),
+		func() bool { return state.Table.GetRowSelected() > -1 },
+		func() {
+			entry := state.Entries[state.Table.GetRowSelected()]
+			if err := deps.Service.RestoreFromTrash(entry); err != nil {
+				log.Println("Error restoring task:", entry.TaskId, err)
+				components.ShowAlertModal(app, pages, fmt.Sprintf("Error restoring the task: %s", err.Error()), nil)
+			}
+			state.Table.Deselect()
+			loadTrash(deps, state, "")
+		},
+	)
+
+	purgeAction := GetNewAction("Purge", NewRuneKey("x", 'x'),
+		func() bool { return state.Table.GetRowSelected() > -1 },
+		func() {
+			handlePurgeTrashEntry(app, pages, deps, state)
+		},
+	)
+
+	nextEntry := GetNewAction("Next Entry", NewRuneKey("j", 'j'),
+		func() bool { return len(state.Entries) > 0 },
+		func() {},
+	)
+
+	prevEntry := GetNewAction("Prev Entry", NewRuneKey("k", 'k'),
+		func() bool { return len(state.Entries) > 0 },
+		func() {},
+	)
+
+	return &[]Action{closeAction, nextEntry, prevEntry, restoreAction, purgeAction}
+}
+
+func handlePurgeTrashEntry(app *tview.Application, pages *tview.Pages, deps *Dependencies, state *TrashState) {
+	entry := state.Entries[state.Table.GetRowSelected()]
+
+	components.ShowConfirmModal(
+		app,
+		pages,
+		"confirmPurge",
+		"Purge this task? It will be deleted for good.",
+		[]string{"Cancel", "Purge"},
+		func(button string) {
+			if button == "Purge" {
+				if err := deps.Service.PurgeTrashEntry(entry); err != nil {
+					log.Println("Error purging trash entry:", err)
+				}
+				loadTrash(deps, state, "")
+			}
+			state.Table.Deselect()
+		},
+	)
+}
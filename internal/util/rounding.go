@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	ROUND_NONE    = "none"
+	ROUND_UP      = "up"
+	ROUND_DOWN    = "down"
+	ROUND_NEAREST = "nearest"
+)
+
+var ROUNDING_MINUTES = []int{1, 5, 6, 15, 30}
+
+// Rounding is a policy applied to the durations reported to integrations. The
+// zero value does not round.
+type Rounding struct {
+	Mode    string `json:"mode"`
+	Minutes int    `json:"minutes"`
+}
+
+func (r Rounding) Validate() error {
+	switch r.Mode {
+	case "", ROUND_NONE:
+		return nil
+	case ROUND_UP, ROUND_DOWN, ROUND_NEAREST:
+	default:
+		return fmt.Errorf("unknown rounding mode: %s", r.Mode)
+	}
+
+	if !slices.Contains(ROUNDING_MINUTES, r.Minutes) {
+		return fmt.Errorf("rounding minutes must be one of %v, got %d", ROUNDING_MINUTES, r.Minutes)
+	}
+	return nil
+}
+
+// Apply rounds a duration in seconds to a multiple of the policy minutes.
+func (r Rounding) Apply(seconds int) int {
+	step := r.Minutes * 60
+	if step <= 0 {
+		return seconds
+	}
+
+	switch r.Mode {
+	case ROUND_UP:
+		return (seconds + step - 1) / step * step
+	case ROUND_DOWN:
+		return seconds / step * step
+	case ROUND_NEAREST:
+		return (seconds + step/2) / step * step
+	}
+	return seconds
+}
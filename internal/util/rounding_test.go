@@ -0,0 +1,45 @@
+package util
+
+import "testing"
+
+func TestRoundingApply(t *testing.T) {
+	tests := []struct {
+		rounding Rounding
+		seconds  int
+		expected int
+	}{
+		{Rounding{}, 4000, 4000},
+		{Rounding{Mode: ROUND_NONE, Minutes: 15}, 4000, 4000},
+		{Rounding{Mode: ROUND_UP, Minutes: 15}, 4000, 4500},
+		{Rounding{Mode: ROUND_UP, Minutes: 15}, 4500, 4500},
+		{Rounding{Mode: ROUND_UP, Minutes: 15}, 0, 0},
+		{Rounding{Mode: ROUND_DOWN, Minutes: 15}, 4000, 3600},
+		{Rounding{Mode: ROUND_NEAREST, Minutes: 15}, 4000, 3600},
+		{Rounding{Mode: ROUND_NEAREST, Minutes: 15}, 4050, 4500},
+		{Rounding{Mode: ROUND_NEAREST, Minutes: 6}, 1000, 1080},
+		{Rounding{Mode: ROUND_UP, Minutes: 1}, 61, 120},
+	}
+
+	for _, test := range tests {
+		result := test.rounding.Apply(test.seconds)
+		if result != test.expected {
+			t.Errorf("Expected %v for %v with %+v, got %v", test.expected, test.seconds, test.rounding, result)
+		}
+	}
+}
+
+func TestRoundingValidate(t *testing.T) {
+	valid := []Rounding{{}, {Mode: ROUND_NONE}, {Mode: ROUND_UP, Minutes: 15}, {Mode: ROUND_NEAREST, Minutes: 6}}
+	for _, rounding := range valid {
+		if err := rounding.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", rounding, err)
+		}
+	}
+
+	invalid := []Rounding{{Mode: "ceil", Minutes: 15}, {Mode: ROUND_UP, Minutes: 10}, {Mode: ROUND_DOWN}}
+	for _, rounding := range invalid {
+		if err := rounding.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", rounding)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var descriptionTag = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+
+// ParseTags splits a list of tags separated by commas or spaces. Tags are
+// lowercased, without the leading #, and returned sorted and without
+// duplicates.
+func ParseTags(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	var tags []string
+	for _, field := range fields {
+		if tag := strings.ToLower(strings.TrimLeft(field, "#")); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// DescriptionTags returns the #tags written in a task description.
+func DescriptionTags(description string) []string {
+	var tags []string
+	for _, match := range descriptionTag.FindAllStringSubmatch(description, -1) {
+		tags = append(tags, match[1])
+	}
+	return ParseTags(strings.Join(tags, ","))
+}
+
+// FormatTags joins tags as they are typed in the task forms.
+func FormatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"", nil},
+		{"meeting", []string{"meeting"}},
+		{"#Review, support  on-call,review", []string{"on-call", "review", "support"}},
+		{" , #", nil},
+	}
+
+	for _, test := range tests {
+		if tags := ParseTags(test.text); !slices.Equal(tags, test.expected) {
+			t.Errorf("ParseTags(%q): expected %v, got %v", test.text, test.expected, tags)
+		}
+	}
+}
+
+func TestDescriptionTags(t *testing.T) {
+	tags := DescriptionTags("#Meeting with the team about issue#12 #review #meeting")
+	if expected := []string{"meeting", "review"}; !slices.Equal(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+}
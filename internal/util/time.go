@@ -18,3 +18,13 @@ func UpdateTime(date *time.Time, hour string) (time.Time, error) {
 		date.Location(),
 	), nil
 }
+
+// WeekBounds returns the Monday and the Sunday of the week of the given date.
+func WeekBounds(date time.Time) (time.Time, time.Time) {
+	weekday := int(date.Weekday()) - 1
+	if weekday < 0 {
+		weekday = 6
+	}
+	monday := date.AddDate(0, 0, -weekday)
+	return monday, monday.AddDate(0, 0, 6)
+}